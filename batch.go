@@ -0,0 +1,119 @@
+package mold
+
+import (
+	"context"
+	"reflect"
+)
+
+// Slice applies transformations against each struct within the provided slice, which must be a non-nil pointer to a
+// slice or array of structs or pointers to structs eg. *[]*Record. The struct information is resolved once for the
+// whole batch and, when enabled using SetConcurrency, the elements are transformed concurrently.
+//
+// Unlike dive, all elements are transformed regardless of errors and an ErrBatch, containing the error of each failed
+// element by index, is returned when any fail.
+func (t *Transformer) Slice(ctx context.Context, v interface{}) error {
+	val := reflect.ValueOf(v)
+
+	if val.Kind() != reflect.Ptr || val.IsNil() {
+		return &ErrInvalidTransformValue{typ: reflect.TypeOf(v), fn: "Slice"}
+	}
+
+	val = val.Elem()
+	if val.Kind() != reflect.Slice && val.Kind() != reflect.Array {
+		return &ErrInvalidTransformation{typ: reflect.TypeOf(v)}
+	}
+
+	typ := derefType(val.Type().Elem())
+	if typ.Kind() != reflect.Struct || typ == timeType {
+		return &ErrInvalidTransformation{typ: reflect.TypeOf(v)}
+	}
+
+	b, err := t.newBatch(typ)
+	if err != nil {
+		return err
+	}
+
+	// nested slices, arrays and maps are not transformed concurrently when the batch itself is
+	parallel := t.parallel(location{}, val.Len())
+
+	errs := make([]error, val.Len())
+	transform := func(i int) error {
+		errs[i] = b.transform(ctx, val.Index(i), parallel)
+		return nil
+	}
+
+	if parallel {
+		_ = t.runParallel(val.Len(), transform)
+	} else {
+		for i := 0; i < val.Len(); i++ {
+			_ = transform(i)
+		}
+	}
+
+	var be ErrBatch
+	for i, err := range errs {
+		if err != nil {
+			be = append(be, &ErrBatchElement{Index: i, Err: err})
+		}
+	}
+
+	if len(be) > 0 {
+		return be
+	}
+	return nil
+}
+
+// batch holds the resolved struct information for transforming many values of the same struct type.
+type batch struct {
+	transformer *Transformer
+	typ         reflect.Type
+	cs          *cStruct
+}
+
+func (t *Transformer) newBatch(typ reflect.Type) (*batch, error) {
+	cs, ok := t.cCache.Get(typ)
+	if !ok {
+		var err error
+		if cs, err = t.extractStructCache(reflect.New(typ).Elem()); err != nil {
+			return nil, err
+		}
+	}
+	return &batch{transformer: t, typ: typ, cs: cs}, nil
+}
+
+// transform applies transformations to a single element of the batch, which may be the struct or a pointer to it.
+// parallel is whether the element is being transformed concurrently with the rest of the batch.
+func (b *batch) transform(ctx context.Context, elem reflect.Value, parallel bool) error {
+	orig := elem
+
+	for elem.Kind() == reflect.Ptr {
+		if elem.IsNil() {
+			return &ErrInvalidTransformValue{typ: orig.Type(), fn: "Slice"}
+		}
+		elem = elem.Elem()
+	}
+
+	if !elem.CanAddr() {
+		newVal := reflect.New(b.typ).Elem()
+		newVal.Set(elem)
+		if err := b.transform(ctx, newVal, parallel); err != nil {
+			return err
+		}
+		orig.Set(newVal)
+		return nil
+	}
+
+	top := orig
+	if top.Kind() != reflect.Ptr {
+		top = elem.Addr()
+	}
+	t := b.transformer
+	loc := location{top: top, name: b.typ.Name(), parallel: parallel}
+
+	if len(t.hooks) > 0 {
+		return t.observeStruct(ctx, loc.name, func(ctx context.Context) error {
+			return t.setByStructCached(ctx, loc, top, elem, b.cs)
+		})
+	}
+	return t.setByStructCached(ctx, loc, top, elem, b.cs)
+}
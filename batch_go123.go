@@ -0,0 +1,40 @@
+//go:build go1.23
+
+package mold
+
+import (
+	"context"
+	"iter"
+	"reflect"
+)
+
+// StructSeq returns an iterator applying transformations against each struct yielded by seq, yielding the
+// struct along with any error that occurred transforming it. The struct information is resolved once for
+// all values and the values are transformed sequentially as the iterator is consumed.
+//
+// eg.
+//
+//	for rec, err := range mold.StructSeq(ctx, transformer, records) {
+//		...
+//	}
+func StructSeq[T any](ctx context.Context, t *Transformer, seq iter.Seq[*T]) iter.Seq2[*T, error] {
+	return func(yield func(*T, error) bool) {
+		typ := reflect.TypeOf((*T)(nil)).Elem()
+
+		var b *batch
+		var err error
+
+		if typ.Kind() != reflect.Struct || typ == timeType {
+			err = &ErrInvalidTransformation{typ: reflect.PtrTo(typ)}
+		} else {
+			b, err = t.newBatch(typ)
+		}
+
+		seq(func(v *T) bool {
+			if err != nil {
+				return yield(v, err)
+			}
+			return yield(v, b.transform(ctx, reflect.ValueOf(v), false))
+		})
+	}
+}
@@ -0,0 +1,42 @@
+//go:build go1.23
+
+package mold
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	. "github.com/go-playground/assert/v2"
+)
+
+func TestStructSeq(t *testing.T) {
+	set := newBatchTransformer()
+
+	recs := []*batchRecord{{}, {Name: "error1"}, {}}
+
+	var errs []error
+	for rec, err := range StructSeq(context.Background(), set, slices.Values(recs)) {
+		NotEqual(t, rec, nil)
+		errs = append(errs, err)
+	}
+	Equal(t, len(errs), 3)
+	Equal(t, errs[0], nil)
+	Equal(t, errs[1].Error(), "error1 at batchRecord.Name")
+	Equal(t, errs[2], nil)
+	Equal(t, recs[0].Name, "test")
+	Equal(t, recs[2].Name, "test")
+
+	// stops when the consumer does
+	recs = []*batchRecord{{}, {}}
+	for range StructSeq(context.Background(), set, slices.Values(recs)) {
+		break
+	}
+	Equal(t, recs[0].Name, "test")
+	Equal(t, recs[1].Name, "")
+
+	ints := []*int{new(int)}
+	for _, err := range StructSeq(context.Background(), set, slices.Values(ints)) {
+		NotEqual(t, err, nil)
+	}
+}
@@ -0,0 +1,141 @@
+package mold
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	. "github.com/go-playground/assert/v2"
+)
+
+type batchRecord struct {
+	Name string `s:"repl"`
+}
+
+func newBatchTransformer() *Transformer {
+	set := New()
+	set.SetTagName("s")
+	set.Register("repl", func(ctx context.Context, fl FieldLevel) error {
+		if strings.HasPrefix(fl.Field().String(), "error") {
+			return errors.New(fl.Field().String() + " at " + fl.Namespace())
+		}
+		fl.Field().SetString("test")
+		return nil
+	})
+	return set
+}
+
+func TestSlice(t *testing.T) {
+	set := newBatchTransformer()
+
+	recs := []batchRecord{{}, {Name: "error1"}, {}, {Name: "error2"}}
+	err := set.Slice(context.Background(), &recs)
+	NotEqual(t, err, nil)
+
+	var be ErrBatch
+	Equal(t, errors.As(err, &be), true)
+	Equal(t, len(be), 2)
+	Equal(t, be[0].Index, 1)
+	Equal(t, be[0].Error(), "index 1: error1 at batchRecord.Name")
+	Equal(t, be[1].Index, 3)
+	Equal(t, err.Error(), "mold: 2 elements failed to transform, first: index 1: error1 at batchRecord.Name")
+	Equal(t, recs[0].Name, "test")
+	Equal(t, recs[2].Name, "test")
+
+	ptrs := []*batchRecord{{}, nil, {}}
+	err = set.Slice(context.Background(), &ptrs)
+	NotEqual(t, err, nil)
+	Equal(t, err.Error(), "mold: 1 element failed to transform: index 1: mold: Slice(nil *mold.batchRecord)")
+	Equal(t, ptrs[0].Name, "test")
+	Equal(t, ptrs[2].Name, "test")
+
+	arr := [2]batchRecord{}
+	err = set.Slice(context.Background(), &arr)
+	Equal(t, err, nil)
+	Equal(t, arr[1].Name, "test")
+
+	var iface []interface{}
+	err = set.Slice(context.Background(), &iface)
+	NotEqual(t, err, nil)
+
+	err = set.Slice(context.Background(), recs)
+	NotEqual(t, err, nil)
+	Equal(t, err.Error(), "mold: Slice(non-pointer []mold.batchRecord)")
+
+	// concurrently
+	set.SetConcurrency(4, 10)
+
+	recs = make([]batchRecord, 1000)
+	recs[500].Name = "error1"
+	recs[10].Name = "error2"
+
+	err = set.Slice(context.Background(), &recs)
+	NotEqual(t, err, nil)
+	Equal(t, errors.As(err, &be), true)
+	Equal(t, len(be), 2)
+	Equal(t, be[0].Error(), "index 10: error2 at batchRecord.Name")
+	Equal(t, be[1].Error(), "index 500: error1 at batchRecord.Name")
+	Equal(t, recs[999].Name, "test")
+
+	// nested slices are not transformed concurrently when the batch is
+	type nestedRecord struct {
+		Names []string `s:"dive,check"`
+	}
+
+	var nestedParallel int32
+	set.Register("check", func(ctx context.Context, fl FieldLevel) error {
+		if !fl.(*fieldLevel).loc.parallel {
+			atomic.StoreInt32(&nestedParallel, 1)
+		}
+		return nil
+	})
+
+	nested := make([]nestedRecord, 20)
+	for i := range nested {
+		nested[i].Names = make([]string, 20)
+	}
+	err = set.Slice(context.Background(), &nested)
+	Equal(t, err, nil)
+	Equal(t, atomic.LoadInt32(&nestedParallel), int32(0))
+}
+
+func TestSliceHooks(t *testing.T) {
+	set := newBatchTransformer()
+	h := &recordingHook{name: "h"}
+	set.RegisterHook(h)
+
+	recs := []batchRecord{{}, {Name: "error1"}, {Name: "test"}}
+	err := set.Slice(context.Background(), &recs)
+	NotEqual(t, err, nil)
+
+	var structs []Event
+	for _, e := range h.events {
+		if e.Kind == EventStruct {
+			structs = append(structs, e)
+		}
+	}
+	Equal(t, len(structs), 3)
+	Equal(t, structs[0].Namespace, "batchRecord")
+	Equal(t, structs[0].Changed, true)
+	Equal(t, structs[1].Err.Error(), "error1 at batchRecord.Name")
+	Equal(t, structs[2].Changed, false)
+}
+
+func TestErrBatchIsAs(t *testing.T) {
+	errSentinel := errors.New("sentinel")
+	err := error(ErrBatch{{Index: 1, Err: &ErrInvalidKeyValuePair{}}, {Index: 2, Err: errSentinel}})
+
+	be := err.(ErrBatch)
+	Equal(t, be.Is(errSentinel), true)
+	Equal(t, be.Is(ErrInvalidDive), false)
+
+	var kv *ErrInvalidKeyValuePair
+	Equal(t, be.As(&kv), true)
+	NotEqual(t, kv, nil)
+
+	var ce *ErrConfig
+	Equal(t, be.As(&ce), false)
+	Equal(t, errors.Is(err, errSentinel), true)
+}
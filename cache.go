@@ -4,7 +4,6 @@ import (
 	"reflect"
 	"strings"
 	"sync"
-	"sync/atomic"
 )
 
 type tagType uint8
@@ -16,58 +15,47 @@ const (
 	typeEndKeys
 )
 
+// structCache caches the compiled information of struct types, keyed by the address of the type as
+// interfaces only satisfy comparable from go1.20.
 type structCache struct {
+	// lock ensures a struct type is only compiled once when transformed concurrently.
 	lock sync.Mutex
-	m    atomic.Value // map[reflect.Type]*cStruct
+	*lruCache[uintptr, *cStruct]
 }
 
-func (sc *structCache) Get(key reflect.Type) (c *cStruct, found bool) {
-	c, found = sc.m.Load().(map[reflect.Type]*cStruct)[key]
-	return
+func (sc *structCache) Get(typ reflect.Type) (c *cStruct, found bool) {
+	return sc.lruCache.Get(typeKey(typ))
 }
 
-func (sc *structCache) Set(key reflect.Type, value *cStruct) {
-
-	m := sc.m.Load().(map[reflect.Type]*cStruct)
+func (sc *structCache) Set(typ reflect.Type, value *cStruct) {
+	sc.lruCache.Set(typeKey(typ), value)
+}
 
-	nm := make(map[reflect.Type]*cStruct, len(m)+1)
-	for k, v := range m {
-		nm[k] = v
-	}
-	nm[key] = value
-	sc.m.Store(nm)
+// typeKey returns the address of the type, which uniquely identifies it.
+func typeKey(typ reflect.Type) uintptr {
+	return reflect.ValueOf(typ).Pointer()
 }
 
+// tagCache caches the compiled tags passed to Field.
 type tagCache struct {
+	// lock ensures the tags are only parsed once when transformed concurrently.
 	lock sync.Mutex
-	m    atomic.Value // map[string]*cTag
-}
-
-func (tc *tagCache) Get(key string) (c *cTag, found bool) {
-	c, found = tc.m.Load().(map[string]*cTag)[key]
-	return
-}
-
-func (tc *tagCache) Set(key string, value *cTag) {
-
-	m := tc.m.Load().(map[string]*cTag)
-
-	nm := make(map[string]*cTag, len(m)+1)
-	for k, v := range m {
-		nm[k] = v
-	}
-	nm[key] = value
-	tc.m.Store(nm)
+	*lruCache[string, *cTag]
 }
 
 type cStruct struct {
+	typ    reflect.Type
 	fields []*cField
 	fn     StructLevelFunc
 }
 
 type cField struct {
-	idx   int
-	cTags *cTag
+	idx        int
+	name       string
+	fld        reflect.StructField
+	cTags      *cTag
+	unexported bool
+	embedded   *cStruct
 }
 
 type cTag struct {
@@ -78,6 +66,7 @@ type cTag struct {
 	typeof         tagType
 	hasTag         bool
 	fn             Func
+	accepts        *acceptance
 	keys           *cTag
 	next           *cTag
 	param          string
@@ -96,8 +85,91 @@ func (t *Transformer) extractStructCache(current reflect.Value) (*cStruct, error
 		return cs, nil
 	}
 
-	cs = &cStruct{fields: make([]*cField, 0), fn: t.structLevelFuncs[typ]}
-	numFields := current.NumField()
+	// the Config rules can only be replaced while the lock is held, see ApplyConfig
+	cs, err := t.compileStruct(t.rules(), typ, "", nil, map[reflect.Type]struct{}{typ: {}})
+	if err != nil {
+		return nil, err
+	}
+
+	t.cCache.Set(typ, cs)
+
+	return cs, nil
+}
+
+// canTransform reports whether traversing a value of the type, within an untagged field, can reach any
+// transformation. A struct type can when it has a StructLevelFunc, a tagged field or a field which can.
+// Interfaces and intercepted types are assumed to, as the values they result in are only known at runtime.
+func (t *Transformer) canTransform(rules *configRules, typ reflect.Type, visited map[reflect.Type]struct{}) bool {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	if typ.Kind() == reflect.Interface || t.interceptor(typ) != nil {
+		return true
+	}
+
+	if typ.Kind() != reflect.Struct || typ == timeType {
+		return false
+	}
+
+	// already visited types either cannot or are still being checked
+	if _, ok := visited[typ]; ok {
+		return false
+	}
+	visited[typ] = struct{}{}
+
+	registered := t.structTags[typ]
+	configured := rules.tags[typ]
+
+	if t.structLevelFuncs[typ] != nil || len(t.promotedTags(typ, registered, configured)) > 0 {
+		return true
+	}
+
+	for i := 0; i < typ.NumField(); i++ {
+		fld := typ.Field(i)
+
+		if !fld.Anonymous && len(fld.PkgPath) > 0 && !t.includeUnexported {
+			continue
+		}
+
+		tag := fld.Tag.Get(t.tagName)
+		if rTag, ok := registered[fld.Name]; ok {
+			tag = rTag
+		}
+		if cTag, ok := configured[fld.Name]; ok {
+			tag = cTag
+		}
+
+		if tag == ignoreTag {
+			continue
+		}
+
+		if len(tag) > 0 || t.canTransform(rules, fld.Type, visited) {
+			return true
+		}
+	}
+	return false
+}
+
+// promotedTag is a tag registered against a promoted field by its index sequence relative to the
+// struct being compiled.
+type promotedTag struct {
+	index []int
+	tag   string
+}
+
+// compileStruct compiles the struct type, appending the inherited tags to each string field and
+// applying the promoted tags registered against the fields of embedded structs by an outer struct.
+// compiling records the embedded struct types on the current path so that structs embedding each
+// other through pointers are not compiled endlessly.
+func (t *Transformer) compileStruct(rules *configRules, typ reflect.Type, inherited string, promoted []promotedTag, compiling map[reflect.Type]struct{}) (*cStruct, error) {
+	cs := &cStruct{typ: typ, fields: make([]*cField, 0), fn: t.structLevelFuncs[typ]}
+	numFields := typ.NumField()
+	registered := t.structTags[typ]
+	configured := rules.tags[typ]
+
+	// tags registered against promoted fields of this struct are overridden by those of any outer struct
+	promoted = append(t.promotedTags(typ, registered, configured), promoted...)
 
 	var ctag *cTag
 	var fld reflect.StructField
@@ -108,19 +180,54 @@ func (t *Transformer) extractStructCache(current reflect.Value) (*cStruct, error
 
 		fld = typ.Field(i)
 
-		if !fld.Anonymous && len(fld.PkgPath) > 0 {
+		unexported := !fld.Anonymous && len(fld.PkgPath) > 0
+		if unexported && !t.includeUnexported {
 			continue
 		}
 
 		tag = fld.Tag.Get(t.tagName)
+		if rTag, ok := registered[fld.Name]; ok {
+			tag = rTag
+		}
+		if cTag, ok := configured[fld.Name]; ok {
+			tag = cTag
+		}
+
+		var embedded []promotedTag
+		for _, p := range promoted {
+			if p.index[0] != i {
+				continue
+			}
+			if len(p.index) == 1 {
+				tag = p.tag
+			} else {
+				embedded = append(embedded, promotedTag{index: p.index[1:], tag: p.tag})
+			}
+		}
+
 		if tag == ignoreTag {
 			continue
 		}
 
+		var promote string
+		if fld.Anonymous {
+			tag, promote = splitPromoteTag(tag)
+		}
+
+		if len(inherited) > 0 && isStringType(fld.Type) {
+			tag = joinTags(tag, inherited)
+		}
+
+		// untagged fields are only traversed, skip those which cannot reach any transformation
+		if len(tag) == 0 && len(promote) == 0 && len(embedded) == 0 && (!fld.Anonymous || len(inherited) == 0) &&
+			!t.canTransform(rules, fld.Type, make(map[reflect.Type]struct{})) {
+			continue
+		}
+
 		// NOTE: cannot use shared tag cache, because tags may be equal, but things like alias may be different
 		// and so only struct level caching can be used instead of combined with Field tag caching
 		if len(tag) > 0 {
-			ctag, _, err = t.parseFieldTagsRecursive(tag, fld.Name, "", false)
+			ctag, _, err = t.parseFieldTagsRecursive(rules, tag, fld.Name, "", false)
 			if err != nil {
 				return nil, err
 			}
@@ -130,18 +237,93 @@ func (t *Transformer) extractStructCache(current reflect.Value) (*cStruct, error
 			ctag = &cTag{typeof: typeDefault}
 		}
 
-		cs.fields = append(cs.fields, &cField{
-			idx:   i,
-			cTags: ctag,
-		})
-	}
+		if t.strict {
+			if err = t.checkKinds(typ.Name()+"."+fld.Name, fld.Type, ctag); err != nil {
+				return nil, err
+			}
+		}
 
-	t.cCache.Set(typ, cs)
+		cf := &cField{
+			idx:        i,
+			name:       fld.Name,
+			fld:        fld,
+			cTags:      ctag,
+			unexported: unexported,
+		}
+
+		if fld.Anonymous {
+			promote = appendTags(promote, inherited)
+			elem := derefType(fld.Type)
+			if _, cycle := compiling[elem]; !cycle && elem.Kind() == reflect.Struct && (len(promote) > 0 || len(embedded) > 0) {
+				compiling[elem] = struct{}{}
+				cf.embedded, err = t.compileStruct(rules, elem, promote, embedded, compiling)
+				delete(compiling, elem)
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		cs.fields = append(cs.fields, cf)
+	}
 
 	return cs, nil
 }
 
-func (t *Transformer) parseFieldTagsRecursive(tag string, fieldName string, alias string, hasAlias bool) (firstCtag *cTag, current *cTag, err error) {
+// promotedTags returns the tags registered against promoted fields of the struct type.
+func (t *Transformer) promotedTags(typ reflect.Type, tags ...map[string]string) (promoted []promotedTag) {
+	for _, m := range tags {
+		for _, name := range sortedKeys(m) {
+			if fld, ok := typ.FieldByName(name); ok && len(fld.Index) > 1 {
+				promoted = append(promoted, promotedTag{index: fld.Index, tag: m[name]})
+			}
+		}
+	}
+	return
+}
+
+// splitPromoteTag splits the tag of an embedded field into the tags applied to the field itself and
+// those following the promote tag, which are applied to each promoted string field.
+func splitPromoteTag(tag string) (own, promote string) {
+	tags := strings.Split(tag, tagSeparator)
+	for i, tg := range tags {
+		if tg == promoteTag {
+			return strings.Join(tags[:i], tagSeparator), strings.Join(tags[i+1:], tagSeparator)
+		}
+	}
+	return tag, ""
+}
+
+func joinTags(a, b string) string {
+	if len(a) == 0 {
+		return b
+	}
+	if len(b) == 0 {
+		return a
+	}
+	return a + tagSeparator + b
+}
+
+// appendTags joins the inherited tags onto the promote tags unless they already end with them.
+func appendTags(promote, inherited string) string {
+	if promote == inherited || strings.HasSuffix(promote, tagSeparator+inherited) {
+		return promote
+	}
+	return joinTags(promote, inherited)
+}
+
+func derefType(typ reflect.Type) reflect.Type {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	return typ
+}
+
+func isStringType(typ reflect.Type) bool {
+	return derefType(typ).Kind() == reflect.String
+}
+
+func (t *Transformer) parseFieldTagsRecursive(rules *configRules, tag string, fieldName string, alias string, hasAlias bool) (firstCtag *cTag, current *cTag, err error) {
 
 	var tg string
 	var ok bool
@@ -156,14 +338,14 @@ func (t *Transformer) parseFieldTagsRecursive(tag string, fieldName string, alia
 		}
 
 		// check map for alias and process new tags, otherwise process as usual
-		if tagsVal, found := t.aliases[tg]; found {
+		if tagsVal, found := t.alias(rules, tg); found {
 			if i == 0 {
-				firstCtag, current, err = t.parseFieldTagsRecursive(tagsVal, fieldName, tg, true)
+				firstCtag, current, err = t.parseFieldTagsRecursive(rules, tagsVal, fieldName, tg, true)
 				if err != nil {
 					return
 				}
 			} else {
-				next, curr, errr := t.parseFieldTagsRecursive(tagsVal, fieldName, tg, true)
+				next, curr, errr := t.parseFieldTagsRecursive(rules, tagsVal, fieldName, tg, true)
 				if errr != nil {
 					err = errr
 					return
@@ -216,7 +398,7 @@ func (t *Transformer) parseFieldTagsRecursive(tag string, fieldName string, alia
 				}
 			}
 
-			if current.keys, _, err = t.parseFieldTagsRecursive(string(b[:len(b)-1]), fieldName, "", false); err != nil {
+			if current.keys, _, err = t.parseFieldTagsRecursive(rules, string(b[:len(b)-1]), fieldName, "", false); err != nil {
 				return
 			}
 			continue
@@ -231,6 +413,10 @@ func (t *Transformer) parseFieldTagsRecursive(tag string, fieldName string, alia
 			}
 			return
 
+		case promoteTag:
+			err = ErrInvalidPromoteTag
+			return
+
 		default:
 
 			vals := strings.SplitN(tg, tagKeySeparator, 2)
@@ -252,6 +438,8 @@ func (t *Transformer) parseFieldTagsRecursive(tag string, fieldName string, alia
 				err = &ErrUndefinedTag{tag: current.tag, field: fieldName}
 				return
 			}
+			current.fn = t.wrap(current.tag, current.fn)
+			current.accepts = t.acceptances[current.tag]
 
 			if len(vals) > 1 {
 				current.param = strings.Replace(vals[1], utf8HexComma, ",", -1)
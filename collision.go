@@ -0,0 +1,82 @@
+package mold
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// KeyCollisionPolicy determines how map entries are handled when transforming map keys, using the keys and endkeys
+// tags, results in two or more keys becoming equal eg. "Foo" and "foo" using lcase.
+type KeyCollisionPolicy uint8
+
+const (
+	// KeyCollisionKeepLast keeps the value of the last colliding key, the default.
+	KeyCollisionKeepLast KeyCollisionPolicy = iota
+
+	// KeyCollisionKeepFirst keeps the value of the first colliding key.
+	KeyCollisionKeepFirst
+
+	// KeyCollisionError returns an *ErrKeyCollision leaving the map unchanged.
+	KeyCollisionError
+
+	// KeyCollisionMerge merges the values of the colliding keys using the KeyMergeFunc.
+	KeyCollisionMerge
+)
+
+// KeyMergeFunc merges the values of two map entries whose keys collide as key, returning the value to store.
+// The returned value must be assignable to the map's value type.
+type KeyMergeFunc func(ctx context.Context, key, existing, value reflect.Value) (reflect.Value, error)
+
+// SetKeyCollisionPolicy sets the policy applied when transforming map keys results in colliding keys, along with the
+// KeyMergeFunc used by KeyCollisionMerge. Colliding keys are resolved in sorted order of the original keys so that first
+// and last are deterministic.
+//
+// NOTES:
+// - this method is not thread-safe it is intended that this be set prior to any transformation
+func (t *Transformer) SetKeyCollisionPolicy(policy KeyCollisionPolicy, merge KeyMergeFunc) {
+	if policy > KeyCollisionMerge {
+		panic(fmt.Sprintf("invalid key collision policy %d", policy))
+	}
+	if policy == KeyCollisionMerge && merge == nil {
+		panic("KeyMergeFunc cannot be empty when using KeyCollisionMerge")
+	}
+	t.keyCollision = policy
+	t.keyMerge = merge
+}
+
+// resolveKeyCollisions returns the map entries with colliding transformed keys resolved using the key collision policy.
+func (t *Transformer) resolveKeyCollisions(ctx context.Context, loc location, entries []mapEntry) ([]mapEntry, error) {
+	seen := make(map[interface{}]int, len(entries))
+	resolved := make([]mapEntry, 0, len(entries))
+
+	for _, e := range entries {
+		k := e.newKey.Interface()
+
+		i, ok := seen[k]
+		if !ok {
+			seen[k] = len(resolved)
+			resolved = append(resolved, e)
+			continue
+		}
+
+		switch t.keyCollision {
+		case KeyCollisionError:
+			return nil, &ErrKeyCollision{
+				Namespace: loc.ns(),
+				Keys:      []interface{}{resolved[i].key.Interface(), e.key.Interface()},
+				Key:       k,
+			}
+		case KeyCollisionKeepFirst:
+		case KeyCollisionMerge:
+			v, err := t.keyMerge(ctx, e.newKey, resolved[i].value, e.value)
+			if err != nil {
+				return nil, err
+			}
+			resolved[i].value = v
+		default:
+			resolved[i].value = e.value
+		}
+	}
+	return resolved, nil
+}
@@ -0,0 +1,306 @@
+package mold
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config defines transformation rules that can be loaded from a configuration document, allowing
+// transformations to be adjusted without code changes.
+//
+// It can be decoded from JSON using DecodeConfig or from YAML using DecodeConfigYAML.
+//
+// eg.
+//
+//	{
+//	  "aliases": {"normalize": "trim,lcase"},
+//	  "types": {
+//	    "pb.User": {"Email": "normalize", "Address.Street": "trim"}
+//	  }
+//	}
+type Config struct {
+	// Aliases maps an alias to the tags it represents, as RegisterAlias does.
+	Aliases map[string]string `json:"aliases,omitempty" yaml:"aliases,omitempty"`
+
+	// Types maps a type name, eg. pb.User or github.com/org/pb.User, to a mapping of field paths to tags.
+	// A field path is a dot separated list of field names eg. Address.Street, traversing through pointers,
+	// slices, arrays and maps to reach the struct containing the final field. A field path crossing a slice,
+	// array or map requires the field holding it to be tagged with a dive for each level, eg. "Addresses": "dive",
+	// otherwise its elements are never reached.
+	//
+	// The tags are registered against the struct type containing the final field in the same way as
+	// RegisterStructTags, so they apply to every value of that type and not only those reached by the path
+	// eg. Address.Street applies to the Street field of every Address.
+	Types map[string]map[string]string `json:"types,omitempty" yaml:"types,omitempty"`
+}
+
+// DecodeConfig decodes a JSON Config document from the provided reader.
+func DecodeConfig(r io.Reader) (cfg Config, err error) {
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+
+	if err = dec.Decode(&cfg); err != nil {
+		var se *json.SyntaxError
+		var te *json.UnmarshalTypeError
+		switch {
+		case errors.As(err, &se):
+			err = &ErrConfig{Location: fmt.Sprintf("offset %d", se.Offset), Err: err}
+		case errors.As(err, &te):
+			err = &ErrConfig{Location: fmt.Sprintf("offset %d", te.Offset), Err: err}
+		}
+	}
+	return
+}
+
+// DecodeConfigYAML decodes a YAML Config document from the provided reader.
+func DecodeConfigYAML(r io.Reader) (cfg Config, err error) {
+	dec := yaml.NewDecoder(r)
+	dec.KnownFields(true)
+
+	if err = dec.Decode(&cfg); err != nil {
+		var te *yaml.TypeError
+		if errors.As(err, &te) && len(te.Errors) > 0 {
+			// report the first of the errors, each of which is prefixed by its line
+			location, msg, _ := strings.Cut(te.Errors[0], ": ")
+			err = &ErrConfig{Location: location, Err: errors.New(msg)}
+		} else if m := yamlLineRegex.FindStringSubmatch(err.Error()); m != nil {
+			err = &ErrConfig{Location: "line " + m[1], Err: err}
+		}
+	}
+	return
+}
+
+var yamlLineRegex = regexp.MustCompile(`^yaml: line (\d+):`)
+
+// ApplyConfig validates the rules within the Config against the registered transformations and aliases and
+// registers them. Types referenced by the Config are resolved from the provided types by name.
+//
+// The rules of any previously applied Config are replaced, allowing rules to be reloaded; rules registered
+// using RegisterAlias or RegisterStructTags are retained but Config rules take precedence over them.
+// When validation fails an *ErrConfig is returned describing the location of the invalid rule and no rules are changed.
+//
+// The rules are replaced atomically, so a Config can be reloaded while transformations are running; those already
+// running may complete using the previous rules.
+//
+// NOTES:
+// - this method must not be called concurrently with the methods registering transformations, aliases or struct tags
+func (t *Transformer) ApplyConfig(cfg Config, types ...interface{}) (err error) {
+	known := make(map[string]reflect.Type, len(types)*2)
+	for _, typ := range types {
+		rt := reflect.TypeOf(typ)
+		for rt != nil && rt.Kind() == reflect.Ptr {
+			rt = rt.Elem()
+		}
+		if rt == nil || rt.Kind() != reflect.Struct {
+			return fmt.Errorf("mold: ApplyConfig requires struct types, %v provided", rt)
+		}
+		known[rt.String()] = rt
+		known[rt.PkgPath()+"."+rt.Name()] = rt
+	}
+
+	// aliases resolve through both those being applied and those registered using RegisterAlias
+	lookup := func(alias string) (string, bool) {
+		if tags, ok := cfg.Aliases[alias]; ok {
+			return tags, true
+		}
+		tags, ok := t.aliases[alias]
+		return tags, ok
+	}
+
+	for _, alias := range sortedKeys(cfg.Aliases) {
+		if _, ok := restrictedTags[alias]; ok || len(alias) == 0 || strings.ContainsAny(alias, restrictedTagChars) {
+			return &ErrConfig{Location: "aliases[" + alias + "]", Err: fmt.Errorf(restrictedAliasErr, alias)}
+		}
+		if len(cfg.Aliases[alias]) == 0 {
+			return &ErrConfig{Location: "aliases[" + alias + "]", Err: errors.New("aliased tags cannot be empty")}
+		}
+		if err = checkAliasCycle(lookup, alias, nil); err != nil {
+			return &ErrConfig{Location: "aliases[" + alias + "]", Err: err}
+		}
+	}
+
+	// validate using the aliases being applied, which are copied so the rules cannot be modified once applied.
+	rules := &configRules{aliases: make(map[string]string, len(cfg.Aliases)), tags: make(map[reflect.Type]map[string]string)}
+	for alias, tags := range cfg.Aliases {
+		rules.aliases[alias] = tags
+	}
+
+	for _, alias := range sortedKeys(cfg.Aliases) {
+		if _, _, err = t.parseFieldTagsRecursive(rules, cfg.Aliases[alias], "", "", false); err != nil {
+			return &ErrConfig{Location: "aliases[" + alias + "]", Err: err}
+		}
+	}
+
+	configTags := rules.tags
+	var crossings []crossing
+
+	for _, name := range sortedKeys(cfg.Types) {
+		rt, ok := known[name]
+		if !ok {
+			return &ErrConfig{Location: "types[" + name + "]", Err: errors.New("unknown type")}
+		}
+
+		fields := cfg.Types[name]
+		for _, path := range sortedKeys(fields) {
+			location := "types[" + name + "]." + path
+
+			owner, field, crossed, err := resolveFieldPath(rt, path)
+			if err != nil {
+				return &ErrConfig{Location: location, Err: err}
+			}
+			for _, c := range crossed {
+				c.location = location
+				crossings = append(crossings, c)
+			}
+
+			tag := fields[path]
+			if tag != ignoreTag {
+				own, promote := splitPromoteTag(tag)
+				for _, tg := range []string{own, promote} {
+					if len(tg) == 0 {
+						continue
+					}
+					if _, _, err = t.parseFieldTagsRecursive(rules, tg, field, "", false); err != nil {
+						return &ErrConfig{Location: location, Err: err}
+					}
+				}
+			}
+
+			m := configTags[owner]
+			if m == nil {
+				m = make(map[string]string)
+				configTags[owner] = m
+			}
+			if existing, ok := m[field]; ok && existing != tag {
+				return &ErrConfig{Location: location, Err: fmt.Errorf("conflicting tags '%s' and '%s' for field %s of type %s", existing, tag, field, owner)}
+			}
+			m[field] = tag
+		}
+	}
+
+	// fields crossing a slice, array or map must dive into their elements for the path to be reached
+	for _, c := range crossings {
+		tag := c.fld.Tag.Get(t.tagName)
+		if rTag, ok := t.structTags[c.owner][c.field]; ok {
+			tag = rTag
+		}
+		if cTag, ok := configTags[c.owner][c.field]; ok {
+			tag = cTag
+		}
+
+		var dives int
+		for _, tg := range strings.Split(tag, tagSeparator) {
+			if tg == diveTag {
+				dives++
+			}
+		}
+		if dives < c.depth {
+			return &ErrConfig{Location: c.location, Err: fmt.Errorf("field %s of type %s must be tagged '%s' to reach its elements", c.field, c.owner, strings.Repeat(diveTag+tagSeparator, c.depth-1)+diveTag)}
+		}
+	}
+
+	// the caches are reset while holding their locks, which are held while compiling, so that no cache
+	// entries compiled using the previous rules remain.
+	t.cCache.lock.Lock()
+	t.tCache.lock.Lock()
+	t.config.Store(rules)
+	t.cCache.reset()
+	t.tCache.reset()
+	t.tCache.lock.Unlock()
+	t.cCache.lock.Unlock()
+	return nil
+}
+
+// configRules is an immutable snapshot of the rules applied using a Config.
+type configRules struct {
+	aliases map[string]string
+	tags    map[reflect.Type]map[string]string
+}
+
+// rules returns the current Config rules.
+func (t *Transformer) rules() *configRules {
+	return t.config.Load().(*configRules)
+}
+
+// crossing is a field within a field path which holds a slice, array or map to be crossed by the path.
+type crossing struct {
+	owner    reflect.Type
+	fld      reflect.StructField
+	field    string
+	depth    int
+	location string
+}
+
+// resolveFieldPath walks the dot separated field path from the provided struct type returning the struct type
+// containing the final field, the final field name and the fields crossing a slice, array or map along the way.
+func resolveFieldPath(typ reflect.Type, path string) (owner reflect.Type, field string, crossed []crossing, err error) {
+	names := strings.Split(path, ".")
+
+	var prev *crossing
+	for i, name := range names {
+		for typ.Kind() == reflect.Ptr || typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array || typ.Kind() == reflect.Map {
+			if typ.Kind() != reflect.Ptr {
+				prev.depth++
+			}
+			typ = typ.Elem()
+		}
+		if prev != nil && prev.depth > 0 {
+			crossed = append(crossed, *prev)
+		}
+
+		if typ.Kind() != reflect.Struct {
+			return nil, "", nil, fmt.Errorf("field %s of type %s is not a struct", strings.Join(names[:i], "."), typ)
+		}
+
+		fld, ok := typ.FieldByName(name)
+		if !ok || (!fld.Anonymous && len(fld.PkgPath) > 0) {
+			return nil, "", nil, fmt.Errorf("field '%s' does not exist or is not exported on type %s", name, typ)
+		}
+
+		if i == len(names)-1 {
+			return typ, name, crossed, nil
+		}
+		prev = &crossing{owner: typ, fld: fld, field: name}
+		typ = fld.Type
+	}
+	return nil, "", nil, errors.New("empty field path")
+}
+
+// checkAliasCycle ensures the alias does not reference itself either directly or through other aliases,
+// resolved using lookup.
+func checkAliasCycle(lookup func(alias string) (string, bool), alias string, seen []string) error {
+	for _, s := range seen {
+		if s == alias {
+			return fmt.Errorf("alias cycle detected %s", strings.Join(append(seen, alias), " -> "))
+		}
+	}
+
+	seen = append(seen, alias)
+
+	tags, _ := lookup(alias)
+	for _, tag := range strings.Split(tags, tagSeparator) {
+		if _, ok := lookup(tag); ok {
+			if err := checkAliasCycle(lookup, tag, seen); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
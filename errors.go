@@ -16,6 +16,9 @@ var (
 
 	// ErrInvalidKeysTag describes a misuse of the keys tag
 	ErrInvalidKeysTag = errors.New("'" + keysTag + "' tag must be immediately preceeded by the '" + diveTag + "' tag")
+
+	// ErrInvalidPromoteTag describes use of the promote tag on a field which is not an embedded struct, or more than once
+	ErrInvalidPromoteTag = errors.New("'" + promoteTag + "' tag can only be used on an embedded struct field, and at most once")
 )
 
 // ErrUndefinedTag defines a tag that does not exist
@@ -69,3 +72,159 @@ type ErrInvalidTransformation struct {
 func (e *ErrInvalidTransformation) Error() string {
 	return "mold: (nil " + e.typ.String() + ")"
 }
+
+// ErrConfig describes an invalid rule within a Config and its location within the Config.
+type ErrConfig struct {
+	Location string
+	Err      error
+}
+
+// Error returns the ErrConfig error text
+func (e *ErrConfig) Error() string {
+	return fmt.Sprintf("mold: invalid config at %s: %s", e.Location, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *ErrConfig) Unwrap() error {
+	return e.Err
+}
+
+// ErrContextDone describes a transformation stopped due to the context being cancelled or its deadline being
+// exceeded, and the namespace of the field at which it stopped.
+type ErrContextDone struct {
+	Namespace string
+	Err       error
+}
+
+// Error returns the ErrContextDone error text
+func (e *ErrContextDone) Error() string {
+	return fmt.Sprintf("mold: transformation stopped at '%s': %s", e.Namespace, e.Err)
+}
+
+// Unwrap returns the underlying context error
+func (e *ErrContextDone) Unwrap() error {
+	return e.Err
+}
+
+// ErrBatch describes the errors which occurred transforming the elements of a batch, ordered by index.
+type ErrBatch []*ErrBatchElement
+
+// Error returns the ErrBatch error text
+func (e ErrBatch) Error() string {
+	if len(e) == 1 {
+		return "mold: 1 element failed to transform: " + e[0].Error()
+	}
+	return fmt.Sprintf("mold: %d elements failed to transform, first: %s", len(e), e[0].Error())
+}
+
+// Unwrap returns the errors of each failed element, used by errors.Is and errors.As on Go 1.20+.
+func (e ErrBatch) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, err := range e {
+		errs[i] = err
+	}
+	return errs
+}
+
+// Is reports whether the error of any failed element matches target, allowing errors.Is to be used prior to Go 1.20.
+func (e ErrBatch) Is(target error) bool {
+	for _, err := range e {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first error of a failed element matching target, allowing errors.As to be used prior to Go 1.20.
+func (e ErrBatch) As(target interface{}) bool {
+	for _, err := range e {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// ErrBatchElement describes the error which occurred transforming an element of a batch.
+type ErrBatchElement struct {
+	Index int
+	Err   error
+}
+
+// Error returns the ErrBatchElement error text
+func (e *ErrBatchElement) Error() string {
+	return fmt.Sprintf("index %d: %s", e.Index, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *ErrBatchElement) Unwrap() error {
+	return e.Err
+}
+
+// ErrKeyCollision describes two map keys which collided after being transformed, when using the
+// KeyCollisionError policy, and the namespace of the map.
+type ErrKeyCollision struct {
+	Namespace string
+	Keys      []interface{}
+	Key       interface{}
+}
+
+// Error returns the ErrKeyCollision error text
+func (e *ErrKeyCollision) Error() string {
+	return fmt.Sprintf("mold: map keys '%v' and '%v' collide as '%v' at '%s'", e.Keys[0], e.Keys[1], e.Key, e.Namespace)
+}
+
+// ErrInvalidKeyValuePair describes a slice or array element which is not a key/value pair struct,
+// containing exported Key and Value fields, when using the keys tag.
+type ErrInvalidKeyValuePair struct {
+	typ reflect.Type
+}
+
+// Error returns the ErrInvalidKeyValuePair error text
+func (e *ErrInvalidKeyValuePair) Error() string {
+	return fmt.Sprintf("mold: '%s' tag requires slice or array elements to be structs with exported Key and Value fields, found %s", keysTag, e.typ)
+}
+
+// ErrPanic describes a panic recovered while transforming, when enabled using SetRecoverPanics, along with the
+// namespace of the field and the tag of the Func being applied, which is empty when not within a Func eg. within
+// a StructLevelFunc or interceptor.
+type ErrPanic struct {
+	Namespace string
+	Tag       string
+	Value     interface{}
+	Stack     []byte
+}
+
+// Error returns the ErrPanic error text
+func (e *ErrPanic) Error() string {
+	if len(e.Tag) == 0 {
+		return fmt.Sprintf("mold: panic at '%s': %v", e.Namespace, e.Value)
+	}
+	return fmt.Sprintf("mold: panic applying '%s' at '%s': %v", e.Tag, e.Namespace, e.Value)
+}
+
+// Unwrap returns the recovered value when it is an error
+func (e *ErrPanic) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// ErrUnsupportedKind describes a Func applied to a value of a type it does not accept, as declared when registered,
+// when strict.
+type ErrUnsupportedKind struct {
+	Namespace string
+	Tag       string
+	Type      reflect.Type
+
+	// dive is whether the Func accepts the elements of the slice, array or map type.
+	dive bool
+}
+
+// Error returns the ErrUnsupportedKind error text
+func (e *ErrUnsupportedKind) Error() string {
+	if e.dive {
+		return fmt.Sprintf("mold: '%s' cannot be applied to %s at '%s', use '%s' to apply it to the elements", e.Tag, e.Type, e.Namespace, diveTag)
+	}
+	return fmt.Sprintf("mold: '%s' cannot be applied to %s at '%s'", e.Tag, e.Type, e.Namespace)
+}
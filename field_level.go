@@ -1,6 +1,11 @@
 package mold
 
-import "reflect"
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"sync"
+)
 
 // FieldLevel represents the interface for field level modifier function
 type FieldLevel interface {
@@ -22,31 +27,212 @@ type FieldLevel interface {
 
 	// Param returns the param associated wth the given function modifier.
 	Param() string
+
+	// Top returns the top level value passed to Struct(), Field() or Document(), being the pointer provided.
+	Top() reflect.Value
+
+	// Struct returns the closest struct containing the current field, or an invalid
+	// reflect.Value when the field is not contained within a struct, eg. when using Field().
+	Struct() reflect.Value
+
+	// FieldName returns the name of the current field including any slice, array or map
+	// index eg. Names[0], or an empty string when not contained within a struct.
+	FieldName() string
+
+	// Namespace returns the full path to the current field starting with the top level
+	// struct name eg. User.Addresses[0].Street, or an empty string when not contained within a struct.
+	Namespace() string
+
+	// StructField returns the reflect.StructField of the current field within the
+	// struct returned by Struct(). The zero value is returned when not contained within a struct.
+	StructField() reflect.StructField
 }
 
 var (
 	_ FieldLevel = (*fieldLevel)(nil)
 )
 
+// location describes where the value currently being transformed resides
+// in relation to the top level value.
+//
+// The namespace is built lazily, when requested, from the containing location and the name, index or
+// map key of the value within it, avoiding allocations unless a namespace or field name is needed.
+type location struct {
+	top reflect.Value
+	st  reflect.Value
+	fld *cField
+
+	// parent is the containing location, when nil path is the namespace of the containing value instead.
+	parent *location
+	path   string
+	name   string
+
+	// elem is whether the location is an element of its parent, identified by idx or mkey, rather than named.
+	elem elemKind
+	idx  int
+	mkey reflect.Value
+
+	// parallel is whether the location is within a slice, array or map being transformed concurrently.
+	parallel bool
+}
+
+type elemKind uint8
+
+const (
+	elemNone elemKind = iota
+	elemIndex
+	elemKey
+)
+
+// locationPool pools the containing locations referenced by nested locations avoiding an allocation
+// for each struct, slice, array or map traversed.
+var locationPool = sync.Pool{
+	New: func() interface{} {
+		return new(location)
+	},
+}
+
+// push returns a pooled copy of the location to be referenced as the parent of nested locations,
+// which must be released once they are no longer in use.
+func (l location) push() *location {
+	p := locationPool.Get().(*location)
+	*p = l
+	return p
+}
+
+func (l *location) release() {
+	*l = location{}
+	locationPool.Put(l)
+}
+
+// ns returns the full namespace of the location.
+func (l location) ns() string {
+	if l.elem != elemNone {
+		return l.parent.ns() + l.elemName()
+	}
+
+	path := l.path
+	if l.parent != nil {
+		path = l.parent.ns()
+	}
+
+	switch {
+	case len(path) == 0:
+		return l.name
+	case len(l.name) == 0:
+		return path
+	default:
+		return path + "." + l.name
+	}
+}
+
+// fieldName returns the name of the location including any slice, array or map index.
+func (l location) fieldName() string {
+	if l.elem != elemNone {
+		return l.parent.fieldName() + l.elemName()
+	}
+	return l.name
+}
+
+func (l location) elemName() string {
+	if l.elem == elemIndex {
+		return "[" + strconv.Itoa(l.idx) + "]"
+	}
+	return "[" + fmt.Sprintf("%v", l.mkey.Interface()) + "]"
+}
+
+// field returns the location of the struct field f of the struct st within the location p.
+func (p *location) field(st reflect.Value, f *cField) location {
+	return location{
+		top:      p.top,
+		st:       st,
+		fld:      f,
+		parent:   p,
+		name:     f.name,
+		parallel: p.parallel,
+	}
+}
+
+// index returns the location of the element at idx of the slice or array within the location p.
+func (p *location) index(idx int) location {
+	return location{top: p.top, st: p.st, fld: p.fld, parent: p, elem: elemIndex, idx: idx, parallel: p.parallel}
+}
+
+// mapKey returns the location of the value identified by key of the map within the location p.
+func (p *location) mapKey(key reflect.Value) location {
+	return location{top: p.top, st: p.st, fld: p.fld, parent: p, elem: elemKey, mkey: key, parallel: p.parallel}
+}
+
+// key returns the location of the value identified by the map key within the current location,
+// as used when no struct fields are involved. Unlike mapKey the namespace is built immediately.
+func (l location) key(key string) location {
+	l.path = l.ns()
+	l.parent, l.elem = nil, elemNone
+	l.name = key
+	return l
+}
+
+// indexName returns the location of the element identified by idx within the current location,
+// as used when no struct fields are involved. Unlike index the name is built immediately.
+func (l location) indexName(idx string) location {
+	l.name += "[" + idx + "]"
+	return l
+}
+
+func (l location) structField() (fld reflect.StructField) {
+	if l.fld != nil {
+		fld = l.fld.fld
+	}
+	return
+}
+
+// fieldLevelPool pools the fieldLevel passed to each Func avoiding an allocation per call.
+var fieldLevelPool = sync.Pool{
+	New: func() interface{} {
+		return new(fieldLevel)
+	},
+}
+
 type fieldLevel struct {
 	transformer *Transformer
 	parent      reflect.Value
 	current     reflect.Value
 	param       string
+	loc         location
 }
 
-func (f fieldLevel) Transformer() Transform {
+func (f *fieldLevel) Transformer() Transform {
 	return f.transformer
 }
 
-func (f fieldLevel) Parent() reflect.Value {
+func (f *fieldLevel) Parent() reflect.Value {
 	return f.parent
 }
 
-func (f fieldLevel) Field() reflect.Value {
+func (f *fieldLevel) Field() reflect.Value {
 	return f.current
 }
 
-func (f fieldLevel) Param() string {
+func (f *fieldLevel) Param() string {
 	return f.param
 }
+
+func (f *fieldLevel) Top() reflect.Value {
+	return f.loc.top
+}
+
+func (f *fieldLevel) Struct() reflect.Value {
+	return f.loc.st
+}
+
+func (f *fieldLevel) FieldName() string {
+	return f.loc.fieldName()
+}
+
+func (f *fieldLevel) Namespace() string {
+	return f.loc.ns()
+}
+
+func (f *fieldLevel) StructField() reflect.StructField {
+	return f.loc.structField()
+}
@@ -0,0 +1,203 @@
+package mold
+
+import (
+	"context"
+	"reflect"
+	"sync/atomic"
+	"time"
+	"unsafe"
+)
+
+// EventKind is the kind of transformation observed by a Hook.
+type EventKind uint8
+
+const (
+	// EventFunc is the application of a Func registered against a tag.
+	EventFunc EventKind = iota
+
+	// EventStruct is a call to Struct, or the transformation of each element by Slice or StructSeq.
+	EventStruct
+)
+
+// Event describes a transformation observed by a Hook.
+type Event struct {
+	Kind EventKind
+
+	// Tag is the tag of the Func applied, empty for EventStruct.
+	Tag string
+
+	// Namespace is the full path to the field the Func was applied to, or the struct name for EventStruct.
+	Namespace string
+
+	// Duration is how long the transformation took.
+	Duration time.Duration
+
+	// Changed reports whether the Func changed the value, as determined by reflect.DeepEqual of a deep copy of the
+	// value before and the value after, so changes made in place to maps, slices and the targets of pointers are
+	// detected, or for EventStruct whether any Func applied during the call did. Unexported fields are not deep copied,
+	// so changes made in place through them are not detected, and non-addressable values obtained through unexported
+	// fields are always reported as unchanged.
+	Changed bool
+
+	// Err is the error returned by the transformation, if any.
+	Err error
+}
+
+// Hook observes transformations allowing metrics to be recorded or tracing to be added, eg. Prometheus counters
+// or OpenTelemetry spans, without wrapping each registered Func.
+type Hook interface {
+	// Start is called prior to each Func applied and each call to Struct, returning the context to use for it
+	// eg. containing a span.
+	Start(ctx context.Context, kind EventKind, tag, namespace string) context.Context
+
+	// End is called after each Func applied and each call to Struct with the context returned by Start.
+	End(ctx context.Context, e Event)
+}
+
+// RegisterHook registers one or more Hooks invoked around each Func applied and each call to Struct. Hooks are started
+// in the order registered and ended in reverse order.
+//
+// NOTES:
+// - hooks must be safe for concurrent use.
+// - this method is not thread-safe it is intended that these all be registered prior to any transformation
+func (t *Transformer) RegisterHook(hooks ...Hook) {
+	for _, h := range hooks {
+		if h == nil {
+			panic("Hook cannot be empty")
+		}
+		t.hooks = append(t.hooks, h)
+	}
+}
+
+// observation records whether any Func changed a value during a call to Struct.
+type observation struct {
+	changed int32
+}
+
+type observationKey struct{}
+
+// startHooks calls Start on each Hook returning the resulting context.
+func (t *Transformer) startHooks(ctx context.Context, kind EventKind, tag, namespace string) context.Context {
+	for _, h := range t.hooks {
+		ctx = h.Start(ctx, kind, tag, namespace)
+	}
+	return ctx
+}
+
+// endHooks calls End on each Hook in reverse order.
+func (t *Transformer) endHooks(ctx context.Context, e Event) {
+	for i := len(t.hooks) - 1; i >= 0; i-- {
+		t.hooks[i].End(ctx, e)
+	}
+}
+
+// observeFunc applies the Func of the tag invoking the registered hooks around it.
+func (t *Transformer) observeFunc(ctx context.Context, loc location, ct *cTag, parent, current reflect.Value) error {
+	ns := loc.ns()
+	ctx = t.startHooks(ctx, EventFunc, ct.tag, ns)
+
+	// values obtained through unexported fields, eg. embedded unexported structs, can only be compared
+	// when addressable, otherwise they are reported as unchanged.
+	observed := current
+	if !observed.CanInterface() && observed.CanAddr() {
+		observed = reflect.NewAt(observed.Type(), unsafe.Pointer(observed.UnsafeAddr())).Elem()
+	}
+
+	var before reflect.Value
+	if observed.CanInterface() {
+		before = reflect.New(observed.Type()).Elem()
+		before.Set(deepCopy(observed, make(map[uintptr]reflect.Value)))
+	}
+
+	start := time.Now()
+	err := t.callFunc(ctx, loc, ct, parent, current)
+	duration := time.Since(start)
+
+	changed := before.IsValid() && !reflect.DeepEqual(before.Interface(), observed.Interface())
+	if obs, ok := ctx.Value(observationKey{}).(*observation); ok && changed {
+		atomic.StoreInt32(&obs.changed, 1)
+	}
+
+	t.endHooks(ctx, Event{Kind: EventFunc, Tag: ct.tag, Namespace: ns, Duration: duration, Changed: changed, Err: err})
+	return err
+}
+
+// deepCopy copies the value along with the maps, slices and pointer targets it references, so changes made to
+// them in place can be detected. Unexported struct fields are copied shallowly.
+func deepCopy(v reflect.Value, visited map[uintptr]reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		if c, ok := visited[v.Pointer()]; ok {
+			return c
+		}
+		c := reflect.New(v.Type().Elem())
+		visited[v.Pointer()] = c
+		c.Elem().Set(deepCopy(v.Elem(), visited))
+		return c
+
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.New(v.Type()).Elem()
+		c.Set(deepCopy(v.Elem(), visited))
+		return c
+
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(deepCopy(v.Index(i), visited))
+		}
+		return c
+
+	case reflect.Array:
+		c := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(deepCopy(v.Index(i), visited))
+		}
+		return c
+
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			c.SetMapIndex(deepCopy(iter.Key(), visited), deepCopy(iter.Value(), visited))
+		}
+		return c
+
+	case reflect.Struct:
+		c := reflect.New(v.Type()).Elem()
+		c.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if c.Field(i).CanSet() {
+				c.Field(i).Set(deepCopy(v.Field(i), visited))
+			}
+		}
+		return c
+
+	default:
+		return v
+	}
+}
+
+// observeStruct calls fn, which transforms the struct, invoking the registered hooks around it.
+func (t *Transformer) observeStruct(ctx context.Context, namespace string, fn func(ctx context.Context) error) error {
+	obs := new(observation)
+	ctx = context.WithValue(t.startHooks(ctx, EventStruct, "", namespace), observationKey{}, obs)
+
+	start := time.Now()
+	err := fn(ctx)
+	duration := time.Since(start)
+
+	t.endHooks(ctx, Event{Kind: EventStruct, Namespace: namespace, Duration: duration, Changed: atomic.LoadInt32(&obs.changed) == 1, Err: err})
+	return err
+}
@@ -0,0 +1,171 @@
+package mold
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	. "github.com/go-playground/assert/v2"
+)
+
+type hookKey struct{}
+
+type recordingHook struct {
+	name   string
+	lock   sync.Mutex
+	calls  []string
+	events []Event
+}
+
+func (h *recordingHook) Start(ctx context.Context, kind EventKind, tag, namespace string) context.Context {
+	h.lock.Lock()
+	h.calls = append(h.calls, h.name+" start "+namespace)
+	h.lock.Unlock()
+	return context.WithValue(ctx, hookKey{}, namespace)
+}
+
+func (h *recordingHook) End(ctx context.Context, e Event) {
+	h.lock.Lock()
+	h.calls = append(h.calls, h.name+" end "+ctx.Value(hookKey{}).(string))
+	h.events = append(h.events, e)
+	h.lock.Unlock()
+}
+
+func TestHooks(t *testing.T) {
+	type Test struct {
+		Name  string `mold:"trim"`
+		Email string `mold:"trim,fail"`
+		Count int    `mold:"noop"`
+	}
+
+	set := New()
+	set.Register("trim", func(ctx context.Context, fl FieldLevel) error {
+		fl.Field().SetString(strings.TrimSpace(fl.Field().String()))
+		return nil
+	})
+	set.Register("noop", func(ctx context.Context, fl FieldLevel) error {
+		// the context returned by the hook is passed along
+		Equal(t, ctx.Value(hookKey{}), "Test.Count")
+		return nil
+	})
+	set.Register("fail", func(ctx context.Context, fl FieldLevel) error {
+		if fl.Field().String() == "fail" {
+			return errors.New("failed")
+		}
+		return nil
+	})
+
+	h1 := &recordingHook{name: "h1"}
+	h2 := &recordingHook{name: "h2"}
+	set.RegisterHook(h1, h2)
+
+	tt := Test{Name: " a ", Email: "b"}
+	err := set.Struct(context.Background(), &tt)
+	Equal(t, err, nil)
+	Equal(t, tt.Name, "a")
+
+	Equal(t, h1.calls[:6], []string{
+		"h1 start Test",
+		"h1 start Test.Name",
+		"h1 end Test.Name",
+		"h1 start Test.Email",
+		"h1 end Test.Email",
+		"h1 start Test.Email",
+	})
+	Equal(t, h2.calls[0], "h2 start Test")
+	Equal(t, h2.calls[len(h2.calls)-1], "h2 end Test")
+
+	Equal(t, len(h1.events), 5)
+	Equal(t, h1.events[0].Kind, EventFunc)
+	Equal(t, h1.events[0].Tag, "trim")
+	Equal(t, h1.events[0].Namespace, "Test.Name")
+	Equal(t, h1.events[0].Changed, true)
+	Equal(t, h1.events[1].Changed, false)
+	Equal(t, h1.events[2].Tag, "fail")
+	Equal(t, h1.events[3].Tag, "noop")
+	Equal(t, h1.events[4].Kind, EventStruct)
+	Equal(t, h1.events[4].Namespace, "Test")
+	Equal(t, h1.events[4].Changed, true)
+	Equal(t, h1.events[4].Duration > 0, true)
+
+	// unchanged and failing
+	h1.events = nil
+	tt.Email = "fail"
+	err = set.Struct(context.Background(), &tt)
+	NotEqual(t, err, nil)
+	Equal(t, len(h1.events), 4)
+	Equal(t, h1.events[2].Err.Error(), "failed")
+	Equal(t, h1.events[3].Kind, EventStruct)
+	Equal(t, h1.events[3].Changed, false)
+	Equal(t, h1.events[3].Err.Error(), "failed")
+
+	// Field only observes Funcs
+	h1.events = nil
+	s := " a "
+	err = set.Field(context.Background(), &s, "trim")
+	Equal(t, err, nil)
+	Equal(t, len(h1.events), 1)
+	Equal(t, h1.events[0].Namespace, "")
+	Equal(t, h1.events[0].Changed, true)
+
+	// changes made in place to maps, slices and pointer targets are detected
+	set.Register("clear", func(ctx context.Context, fl FieldLevel) error {
+		switch fl.Field().Kind() {
+		case reflect.Map:
+			for _, k := range fl.Field().MapKeys() {
+				fl.Field().SetMapIndex(k, reflect.Value{})
+			}
+		case reflect.Slice:
+			reflect.Indirect(fl.Field().Index(0)).SetString("")
+		}
+		return nil
+	})
+
+	type InPlace struct {
+		Map   map[string]string `mold:"clear"`
+		Slice []string          `mold:"clear"`
+		Ptrs  []*string         `mold:"clear"`
+	}
+
+	h1.events = nil
+	p := "p"
+	ip := InPlace{Map: map[string]string{"a": "b"}, Slice: []string{"a"}, Ptrs: []*string{&p}}
+	err = set.Struct(context.Background(), &ip)
+	Equal(t, err, nil)
+	Equal(t, len(ip.Map), 0)
+	Equal(t, p, "")
+	Equal(t, len(h1.events), 4)
+	Equal(t, h1.events[0].Changed, true)
+	Equal(t, h1.events[1].Changed, true)
+	Equal(t, h1.events[2].Changed, true)
+	Equal(t, h1.events[3].Changed, true)
+
+	// values obtained through unexported embedded fields
+	type inner struct {
+		Name string
+	}
+
+	type Outer struct {
+		inner `mold:"clear"`
+	}
+
+	set.Register("clear", func(ctx context.Context, fl FieldLevel) error {
+		if fl.Field().Kind() == reflect.Struct {
+			fl.Field().Field(0).SetString("")
+		}
+		return nil
+	})
+
+	h1.events = nil
+	o := Outer{inner: inner{Name: "name"}}
+	err = set.Struct(context.Background(), &o)
+	Equal(t, err, nil)
+	Equal(t, o.Name, "")
+	Equal(t, len(h1.events), 2)
+	Equal(t, h1.events[0].Changed, true)
+
+	PanicMatches(t, func() { set.RegisterHook(nil) }, "Hook cannot be empty")
+}
@@ -0,0 +1,196 @@
+package mold
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	interceptableType = reflect.TypeOf((*Interceptable)(nil)).Elem()
+	writeBackerType   = reflect.TypeOf((*WriteBacker)(nil)).Elem()
+)
+
+// Interceptable is implemented by custom types, such as generic optional/nullable wrappers, that wrap an inner value to
+// which transformations should be applied. No registration is required for types implementing it.
+//
+// eg.
+//
+//	func (o *Optional[T]) MoldInner() reflect.Value {
+//		return reflect.ValueOf(&o.value).Elem()
+//	}
+type Interceptable interface {
+	MoldInner() reflect.Value
+}
+
+// WriteBacker may be implemented by an Interceptable custom type to be notified after each transformation of the inner
+// value returned by MoldInner, in the same way as a WriteBackFunc.
+type WriteBacker interface {
+	MoldWriteBack(inner reflect.Value) error
+}
+
+// MatchFunc reports whether an interceptor applies to the provided type.
+type MatchFunc func(typ reflect.Type) bool
+
+type matchInterceptor struct {
+	match MatchFunc
+	ic    *interceptor
+}
+
+type interceptorCache struct {
+	lock sync.Mutex
+	m    atomic.Value // map[reflect.Type]*interceptor
+}
+
+func (ic *interceptorCache) Get(key reflect.Type) (c *interceptor, found bool) {
+	c, found = ic.m.Load().(map[reflect.Type]*interceptor)[key]
+	return
+}
+
+func (ic *interceptorCache) Set(key reflect.Type, value *interceptor) {
+
+	m := ic.m.Load().(map[reflect.Type]*interceptor)
+
+	nm := make(map[reflect.Type]*interceptor, len(m)+1)
+	for k, v := range m {
+		nm[k] = v
+	}
+	nm[key] = value
+	ic.m.Store(nm)
+}
+
+func (ic *interceptorCache) reset() {
+	ic.lock.Lock()
+	ic.m.Store(make(map[reflect.Type]*interceptor))
+	ic.lock.Unlock()
+}
+
+// RegisterInterceptorMatch registers a new interceptor function, along with an optional WriteBackFunc, against all types for
+// which match returns true. This allows a single registration for all instantiations of a generic type or types sharing
+// a common shape, where RegisterInterceptor requires the exact type.
+//
+// Interceptors registered against an exact type take precedence, followed by those registered using this method in the
+// order they were registered.
+//
+// NOTES:
+// - this method is not thread-safe it is intended that these all be registered prior to any transformation
+func (t *Transformer) RegisterInterceptorMatch(match MatchFunc, fn InterceptorFunc, writeBack WriteBackFunc) {
+	if match == nil || fn == nil {
+		panic("Match and interceptor functions cannot be empty")
+	}
+	t.matchInterceptors = append(t.matchInterceptors, matchInterceptor{match: match, ic: &interceptor{fn: fn, writeBack: writeBack}})
+	t.iCache.reset()
+	t.resetCaches()
+}
+
+// RegisterInterceptorImplements registers a new interceptor function, along with an optional WriteBackFunc, against all types
+// which, or whose pointer, implement the provided interface; iface must be a nil pointer to the interface eg. (*MyInterface)(nil).
+func (t *Transformer) RegisterInterceptorImplements(iface interface{}, fn InterceptorFunc, writeBack WriteBackFunc) {
+	it := reflect.TypeOf(iface)
+	if it == nil || it.Kind() != reflect.Ptr || it.Elem().Kind() != reflect.Interface {
+		panic(fmt.Sprintf("RegisterInterceptorImplements requires a nil pointer to an interface, %v provided", it))
+	}
+	t.RegisterInterceptorMatch(Implements(it.Elem()), fn, writeBack)
+}
+
+// RegisterValueSetterInterceptor registers an interceptor against all types with a `Value() T` method and a `Set(T)` method,
+// commonly used by generic optional wrappers. Transformations are applied to a copy of the value returned by Value, which is
+// written back using Set.
+func (t *Transformer) RegisterValueSetterInterceptor() {
+	t.RegisterInterceptorMatch(isValueSetter, valueSetterInner, valueSetterWriteBack)
+}
+
+// Implements returns a MatchFunc matching types which, or whose pointer, implement the provided interface type.
+func Implements(iface reflect.Type) MatchFunc {
+	return func(typ reflect.Type) bool {
+		return typ.Implements(iface) || (typ.Kind() != reflect.Ptr && reflect.PtrTo(typ).Implements(iface))
+	}
+}
+
+// interceptor returns the interceptor for the provided type, if any.
+func (t *Transformer) interceptor(typ reflect.Type) *interceptor {
+	if ic, ok := t.interceptors[typ]; ok {
+		return ic
+	}
+
+	ic, ok := t.iCache.Get(typ)
+	if ok {
+		return ic
+	}
+
+	t.iCache.lock.Lock()
+	defer t.iCache.lock.Unlock()
+
+	if ic, ok = t.iCache.Get(typ); ok {
+		return ic
+	}
+
+	for _, mi := range t.matchInterceptors {
+		if mi.match(typ) {
+			ic = mi.ic
+			break
+		}
+	}
+
+	if ic == nil && Implements(interceptableType)(typ) {
+		ic = &interceptor{fn: interceptableInner}
+		if Implements(writeBackerType)(typ) {
+			ic.writeBack = writeBackerWriteBack
+		}
+	}
+
+	t.iCache.Set(typ, ic)
+	return ic
+}
+
+// addressable returns an addressable version of current, copying it when necessary.
+func addressable(current reflect.Value) reflect.Value {
+	if current.CanAddr() {
+		return current.Addr()
+	}
+	newVal := reflect.New(current.Type())
+	newVal.Elem().Set(current)
+	return newVal
+}
+
+func interceptableInner(current reflect.Value) reflect.Value {
+	if current.Type().Implements(interceptableType) {
+		return current.Interface().(Interceptable).MoldInner()
+	}
+	return addressable(current).Interface().(Interceptable).MoldInner()
+}
+
+func writeBackerWriteBack(current, inner reflect.Value) error {
+	if current.Type().Implements(writeBackerType) {
+		return current.Interface().(WriteBacker).MoldWriteBack(inner)
+	}
+	return current.Addr().Interface().(WriteBacker).MoldWriteBack(inner)
+}
+
+func isValueSetter(typ reflect.Type) bool {
+	ptr := typ
+	if typ.Kind() != reflect.Ptr {
+		ptr = reflect.PtrTo(typ)
+	}
+
+	value, ok := ptr.MethodByName("Value")
+	if !ok || value.Type.NumIn() != 1 || value.Type.NumOut() != 1 {
+		return false
+	}
+
+	set, ok := ptr.MethodByName("Set")
+	return ok && set.Type.NumIn() == 2 && set.Type.NumOut() == 0 && set.Type.In(1) == value.Type.Out(0)
+}
+
+func valueSetterInner(current reflect.Value) reflect.Value {
+	v := addressable(current).MethodByName("Value").Call(nil)[0]
+	inner := reflect.New(v.Type()).Elem()
+	inner.Set(v)
+	return inner
+}
+
+func valueSetterWriteBack(current, inner reflect.Value) error {
+	current.Addr().MethodByName("Set").Call([]reflect.Value{inner})
+	return nil
+}
@@ -0,0 +1,154 @@
+package mold
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	. "github.com/go-playground/assert/v2"
+)
+
+type optional[T any] struct {
+	value T
+	set   bool
+}
+
+func (o optional[T]) Value() T {
+	return o.value
+}
+
+func (o *optional[T]) Set(value T) {
+	o.value, o.set = value, true
+}
+
+type nullable[T any] struct {
+	value T
+	valid bool
+}
+
+func (n *nullable[T]) MoldInner() reflect.Value {
+	return reflect.ValueOf(&n.value).Elem()
+}
+
+func (n *nullable[T]) MoldWriteBack(inner reflect.Value) error {
+	if inner.IsZero() {
+		return errors.New("zero value")
+	}
+	n.valid = true
+	return nil
+}
+
+type plain[T any] struct {
+	value T
+}
+
+func (p *plain[T]) MoldInner() reflect.Value {
+	return reflect.ValueOf(&p.value).Elem()
+}
+
+type wrapped interface {
+	Wrapped() reflect.Value
+}
+
+type custom struct {
+	Value string
+}
+
+func (c *custom) Wrapped() reflect.Value {
+	return reflect.ValueOf(&c.Value).Elem()
+}
+
+func TestGenericInterceptors(t *testing.T) {
+	set := New()
+	set.RegisterValueSetterInterceptor()
+	set.Register("upper", func(ctx context.Context, fl FieldLevel) error {
+		if fl.Field().Kind() == reflect.String {
+			fl.Field().SetString(strings.ToUpper(fl.Field().String()))
+		}
+		return nil
+	})
+	set.Register("inc", func(ctx context.Context, fl FieldLevel) error {
+		if fl.Field().Kind() == reflect.Int {
+			fl.Field().SetInt(fl.Field().Int() + 1)
+		}
+		return nil
+	})
+	set.Register("empty", func(ctx context.Context, fl FieldLevel) error {
+		fl.Field().Set(reflect.Zero(fl.Field().Type()))
+		return nil
+	})
+
+	type Test struct {
+		String   optional[string] `mold:"upper"`
+		Int      optional[int]    `mold:"inc"`
+		Nullable nullable[string] `mold:"upper"`
+		Plain    plain[int]       `mold:"inc"`
+		Ptr      *plain[string]   `mold:"upper"`
+		Dive     []optional[int]  `mold:"dive,inc"`
+	}
+
+	tt := Test{
+		String:   optional[string]{value: "a"},
+		Nullable: nullable[string]{value: "b"},
+		Ptr:      &plain[string]{value: "c"},
+		Dive:     make([]optional[int], 2),
+	}
+
+	err := set.Struct(context.Background(), &tt)
+	Equal(t, err, nil)
+	Equal(t, tt.String, optional[string]{value: "A", set: true})
+	Equal(t, tt.Int, optional[int]{value: 1, set: true})
+	Equal(t, tt.Nullable, nullable[string]{value: "B", valid: true})
+	Equal(t, tt.Plain, plain[int]{value: 1})
+	Equal(t, tt.Ptr, &plain[string]{value: "C"})
+	Equal(t, tt.Dive, []optional[int]{{value: 1, set: true}, {value: 1, set: true}})
+
+	var n nullable[string]
+	err = set.Field(context.Background(), &n, "empty")
+	NotEqual(t, err, nil)
+	Equal(t, err.Error(), "zero value")
+}
+
+func TestInterceptorMatch(t *testing.T) {
+	set := New()
+	set.Register("upper", func(ctx context.Context, fl FieldLevel) error {
+		if fl.Field().Kind() == reflect.String {
+			fl.Field().SetString(strings.ToUpper(fl.Field().String()))
+		}
+		return nil
+	})
+
+	var calls []string
+
+	set.RegisterInterceptorImplements((*wrapped)(nil), func(current reflect.Value) reflect.Value {
+		calls = append(calls, "implements")
+		return current.Addr().Interface().(wrapped).Wrapped()
+	}, nil)
+	set.RegisterInterceptorMatch(func(typ reflect.Type) bool {
+		return typ == reflect.TypeOf(custom{})
+	}, func(current reflect.Value) reflect.Value {
+		calls = append(calls, "match")
+		return current.Field(0)
+	}, nil)
+
+	c := custom{Value: "a"}
+	err := set.Field(context.Background(), &c, "upper")
+	Equal(t, err, nil)
+	Equal(t, c.Value, "A")
+	Equal(t, calls, []string{"implements"})
+
+	// exact types take precedence
+	set.RegisterInterceptor(func(current reflect.Value) reflect.Value {
+		calls = append(calls, "exact")
+		return current.Field(0)
+	}, custom{})
+
+	err = set.Field(context.Background(), &c, "upper")
+	Equal(t, err, nil)
+	Equal(t, calls, []string{"implements", "exact"})
+
+	PanicMatches(t, func() { set.RegisterInterceptorMatch(nil, nil, nil) }, "Match and interceptor functions cannot be empty")
+	PanicMatches(t, func() { set.RegisterInterceptorImplements(custom{}, nil, nil) }, "RegisterInterceptorImplements requires a nil pointer to an interface, mold.custom provided")
+}
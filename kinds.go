@@ -0,0 +1,195 @@
+package mold
+
+import (
+	"context"
+	"reflect"
+)
+
+// RegisterOption configures a Func when registered using Register.
+type RegisterOption func(a *acceptance)
+
+// Kinds declares the kinds of values the Func accepts eg. Kinds(reflect.String). Applying the Func to a value of any
+// other kind, or type not declared using Types, is skipped or, when strict, results in an *ErrUnsupportedKind.
+func Kinds(kinds ...reflect.Kind) RegisterOption {
+	return func(a *acceptance) {
+		a.kinds = append(a.kinds, kinds...)
+	}
+}
+
+// Types declares the types of values the Func accepts eg. Types(time.Time{}), in addition to any declared using Kinds.
+func Types(types ...interface{}) RegisterOption {
+	return func(a *acceptance) {
+		for _, typ := range types {
+			a.types = append(a.types, reflect.TypeOf(typ))
+		}
+	}
+}
+
+// Bytes declares the Func accepts byte slices eg. []byte and json.RawMessage, in addition to any kinds or types
+// declared using Kinds or Types.
+func Bytes() RegisterOption {
+	return func(a *acceptance) {
+		a.bytes = true
+	}
+}
+
+// ElementWise declares the Func, whose accepted kinds or types are declared using Kinds or Types, is applied to
+// each element of slices and arrays, and each value of maps, whose element type it accepts without requiring the
+// dive tag eg. `mold:"trim"` on a []string.
+func ElementWise() RegisterOption {
+	return func(a *acceptance) {
+		a.elementWise = true
+	}
+}
+
+// acceptance holds the kinds and types of values accepted by a Func.
+type acceptance struct {
+	kinds       []reflect.Kind
+	types       []reflect.Type
+	bytes       bool
+	elementWise bool
+}
+
+func (a *acceptance) accepts(typ reflect.Type) bool {
+	if a.bytes && isBytes(typ) {
+		return true
+	}
+	for _, k := range a.kinds {
+		if typ.Kind() == k {
+			return true
+		}
+	}
+	for _, at := range a.types {
+		if typ == at {
+			return true
+		}
+	}
+	return false
+}
+
+// acceptsElements reports whether the Func is applied to each element of the slice, array or map type, the element type,
+// or those of nested slices, arrays or maps, being accepted. Interface elements are checked at runtime.
+func (a *acceptance) acceptsElements(typ reflect.Type) bool {
+	if !a.elementWise {
+		return false
+	}
+
+	switch typ.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		elem := typ.Elem()
+		for elem.Kind() == reflect.Ptr {
+			elem = elem.Elem()
+		}
+		return elem.Kind() == reflect.Interface || a.accepts(elem) || a.acceptsElements(elem)
+	}
+	return false
+}
+
+// SetStrict sets whether applying a Func to a value it does not accept, as declared when registered using Kinds or Types,
+// results in an error rather than being skipped, default is false. When strict, struct fields whose types are not accepted
+// result in an *ErrUnsupportedKind when the struct is first transformed, otherwise when the Func would be applied.
+//
+// NOTES:
+// - this method is not thread-safe it is intended that this be set prior to any transformation
+func (t *Transformer) SetStrict(strict bool) {
+	t.strict = strict
+	t.resetCaches()
+}
+
+// accepts reports whether the Func of the tag accepts the current value, returning an *ErrUnsupportedKind when
+// strict and it does not. Nil pointers, whose element type is accepted, are skipped as there is no value to apply it to.
+func (t *Transformer) accepts(loc location, ct *cTag, current reflect.Value) (bool, error) {
+	if ct.accepts == nil || !current.IsValid() {
+		return true, nil
+	}
+
+	typ := current.Type()
+	if typ.Kind() == reflect.Interface || ct.accepts.accepts(typ) {
+		return true, nil
+	}
+
+	if typ.Kind() == reflect.Ptr {
+		for typ.Kind() == reflect.Ptr {
+			typ = typ.Elem()
+		}
+		if typ.Kind() == reflect.Interface || ct.accepts.accepts(typ) {
+			return false, nil
+		}
+	}
+
+	if t.strict {
+		return false, newErrUnsupportedKind(loc.ns(), ct, current.Type())
+	}
+	return false, nil
+}
+
+// callElements applies the element-wise Func of the tag to each element of the slice, array or map.
+func (t *Transformer) callElements(ctx context.Context, loc location, ct *cTag, current reflect.Value) error {
+	elem := *ct
+	elem.next = nil
+
+	if current.Kind() == reflect.Map {
+		return t.setByMap(ctx, loc, current, &elem)
+	}
+	return t.setByIterable(ctx, loc, current, &elem)
+}
+
+// checkKinds validates that the Funcs of the tags accept the field type, and those reached through dive,
+// stopping at interfaces and intercepted types as the values they result in are only known at runtime.
+func (t *Transformer) checkKinds(namespace string, typ reflect.Type, ct *cTag) error {
+	for ; ct != nil && ct.hasTag; ct = ct.next {
+		for typ.Kind() == reflect.Ptr {
+			typ = typ.Elem()
+		}
+
+		if typ.Kind() == reflect.Interface || t.interceptor(typ) != nil {
+			return nil
+		}
+
+		switch ct.typeof {
+		case typeDive:
+			switch typ.Kind() {
+			case reflect.Slice, reflect.Array:
+				if ct.next != nil && ct.next.typeof == typeKeys {
+					// key/value pairs are checked at runtime
+					return nil
+				}
+				namespace += "[]"
+			case reflect.Map:
+				if ct.next != nil && ct.next.typeof == typeKeys {
+					if err := t.checkKinds(namespace+"[]", typ.Key(), ct.next.keys); err != nil {
+						return err
+					}
+					ct = ct.next
+				}
+				namespace += "[]"
+			default:
+				return nil
+			}
+			typ = typ.Elem()
+
+		case typeKeys, typeEndKeys:
+			return nil
+
+		default:
+			if ct.accepts != nil && !ct.accepts.accepts(typ) && !ct.accepts.acceptsElements(typ) {
+				return newErrUnsupportedKind(namespace, ct, typ)
+			}
+		}
+	}
+	return nil
+}
+
+func newErrUnsupportedKind(namespace string, ct *cTag, typ reflect.Type) *ErrUnsupportedKind {
+	err := &ErrUnsupportedKind{Namespace: namespace, Tag: ct.tag, Type: typ}
+
+	switch typ.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		elem := typ.Elem()
+		for elem.Kind() == reflect.Ptr {
+			elem = elem.Elem()
+		}
+		err.dive = ct.accepts.accepts(elem)
+	}
+	return err
+}
@@ -0,0 +1,201 @@
+package mold
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	cacheShards = 16
+
+	// DefaultTagCacheCapacity is the default number of distinct tags, passed to Field, that are cached.
+	DefaultTagCacheCapacity = 4096
+)
+
+// CacheStats describes the usage of a cache.
+type CacheStats struct {
+	// Len is the number of entries currently cached.
+	Len int
+
+	// Capacity is the maximum number of entries cached, 0 being unbounded.
+	Capacity int
+
+	// Hits is the number of lookups which found a cached entry.
+	Hits uint64
+
+	// Misses is the number of lookups which did not find a cached entry.
+	Misses uint64
+
+	// Evictions is the number of entries evicted to make room for new ones.
+	Evictions uint64
+}
+
+// SetCacheCapacity sets the maximum number of distinct tags, passed to Field, and struct types whose compiled
+// information is cached; 0 means unbounded. The tag cache defaults to DefaultTagCacheCapacity and the struct
+// cache to unbounded, as the number of struct types is fixed by the program but tags can be built dynamically.
+//
+// The capacity is divided between a number of shards, reducing contention, and when a shard is full its least recently
+// used entries are approximately evicted. Any currently cached information is discarded. Usage, including the number of
+// evictions, is reported by CacheStats.
+//
+// NOTES:
+// - this method is not thread-safe it is intended that this be set prior to any transformation
+func (t *Transformer) SetCacheCapacity(tags, structs int) {
+	if tags < 0 || structs < 0 {
+		panic("cache capacity cannot be negative")
+	}
+	t.tCache = &tagCache{lruCache: newLRUCache[string, *cTag](tags, hashString)}
+	t.cCache = &structCache{lruCache: newLRUCache[uintptr, *cStruct](structs, hashPointer)}
+}
+
+// CacheStats returns the usage of the tag and struct caches.
+func (t *Transformer) CacheStats() (tags, structs CacheStats) {
+	return t.tCache.stats(), t.cCache.stats()
+}
+
+// lruCache is a sharded, concurrent and optionally bounded cache. Lookups only take a shard's read lock and
+// entries are evicted using the CLOCK algorithm, an approximation of least recently used.
+type lruCache[K comparable, V any] struct {
+	shards   []*lruShard[K, V]
+	hash     func(K) uint64
+	capacity int
+}
+
+type lruShard[K comparable, V any] struct {
+	hits      uint64
+	misses    uint64
+	evictions uint64
+	lock      sync.RWMutex
+	m         map[K]*lruEntry[K, V]
+	ring      []*lruEntry[K, V]
+	hand      int
+	capacity  int
+}
+
+type lruEntry[K comparable, V any] struct {
+	key   K
+	value V
+	ref   uint32
+}
+
+func newLRUCache[K comparable, V any](capacity int, hash func(K) uint64) *lruCache[K, V] {
+	n := cacheShards
+	if capacity > 0 && capacity < n {
+		n = capacity
+	}
+
+	c := &lruCache[K, V]{shards: make([]*lruShard[K, V], n), hash: hash, capacity: capacity}
+
+	for i := range c.shards {
+		shardCap := 0
+		if capacity > 0 {
+			// distribute the capacity so that the shards total it exactly
+			shardCap = capacity / n
+			if i < capacity%n {
+				shardCap++
+			}
+		}
+		c.shards[i] = &lruShard[K, V]{m: make(map[K]*lruEntry[K, V]), capacity: shardCap}
+	}
+	return c
+}
+
+func (c *lruCache[K, V]) shard(key K) *lruShard[K, V] {
+	return c.shards[c.hash(key)%uint64(len(c.shards))]
+}
+
+func (c *lruCache[K, V]) Get(key K) (value V, found bool) {
+	s := c.shard(key)
+
+	s.lock.RLock()
+	e, found := s.m[key]
+	if found {
+		value = e.value
+		if atomic.LoadUint32(&e.ref) == 0 {
+			atomic.StoreUint32(&e.ref, 1)
+		}
+	}
+	s.lock.RUnlock()
+
+	if found {
+		atomic.AddUint64(&s.hits, 1)
+	} else {
+		atomic.AddUint64(&s.misses, 1)
+	}
+	return
+}
+
+func (c *lruCache[K, V]) Set(key K, value V) {
+	s := c.shard(key)
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if e, ok := s.m[key]; ok {
+		e.value = value
+		return
+	}
+
+	e := &lruEntry[K, V]{key: key, value: value}
+	s.m[key] = e
+
+	switch {
+	case s.capacity == 0:
+	case len(s.ring) < s.capacity:
+		s.ring = append(s.ring, e)
+	default:
+		// give recently referenced entries a second chance, evicting the first which has not been
+		for {
+			old := s.ring[s.hand]
+			if atomic.LoadUint32(&old.ref) == 1 {
+				atomic.StoreUint32(&old.ref, 0)
+				s.hand = (s.hand + 1) % len(s.ring)
+				continue
+			}
+			delete(s.m, old.key)
+			s.ring[s.hand] = e
+			s.hand = (s.hand + 1) % len(s.ring)
+			atomic.AddUint64(&s.evictions, 1)
+			break
+		}
+	}
+}
+
+func (c *lruCache[K, V]) reset() {
+	for _, s := range c.shards {
+		s.lock.Lock()
+		s.m = make(map[K]*lruEntry[K, V])
+		s.ring = nil
+		s.hand = 0
+		s.lock.Unlock()
+	}
+}
+
+func (c *lruCache[K, V]) stats() (st CacheStats) {
+	st.Capacity = c.capacity
+	for _, s := range c.shards {
+		s.lock.RLock()
+		st.Len += len(s.m)
+		s.lock.RUnlock()
+		st.Hits += atomic.LoadUint64(&s.hits)
+		st.Misses += atomic.LoadUint64(&s.misses)
+		st.Evictions += atomic.LoadUint64(&s.evictions)
+	}
+	return
+}
+
+// hashString returns the FNV-1a hash of the string.
+func hashString(s string) uint64 {
+	h := uint64(14695981039346656037)
+	for i := 0; i < len(s); i++ {
+		h ^= uint64(s[i])
+		h *= 1099511628211
+	}
+	return h
+}
+
+// hashPointer returns a hash of the address.
+func hashPointer(p uintptr) uint64 {
+	h := uint64(p)
+	return h ^ h>>17 ^ h>>31
+}
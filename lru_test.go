@@ -0,0 +1,111 @@
+package mold
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	. "github.com/go-playground/assert/v2"
+)
+
+func TestLRUCache(t *testing.T) {
+	c := newLRUCache[string, int](3, hashString)
+	Equal(t, len(c.shards), 3)
+
+	for i := 0; i < 100; i++ {
+		c.Set(strconv.Itoa(i), i)
+	}
+
+	st := c.stats()
+	Equal(t, st.Len, 3)
+	Equal(t, st.Capacity, 3)
+	Equal(t, st.Evictions, uint64(97))
+
+	// recently referenced entries are given a second chance
+	c = newLRUCache[string, int](1, hashString)
+	c.Set("a", 1)
+	c.Set("b", 2)
+	_, ok := c.Get("a")
+	Equal(t, ok, false)
+	v, ok := c.Get("b")
+	Equal(t, ok, true)
+	Equal(t, v, 2)
+
+	c = newLRUCache[string, int](32, hashString)
+	for i := 0; i < 32; i++ {
+		c.Set(strconv.Itoa(i), i)
+	}
+	// the capacity is divided between the shards
+	st = c.stats()
+	Equal(t, st.Len+int(st.Evictions), 32)
+	Equal(t, st.Len <= 32, true)
+
+	c.reset()
+	Equal(t, c.stats().Len, 0)
+
+	// unbounded
+	c = newLRUCache[string, int](0, hashString)
+	for i := 0; i < 1000; i++ {
+		c.Set(strconv.Itoa(i), i)
+	}
+	st = c.stats()
+	Equal(t, st.Len, 1000)
+	Equal(t, st.Capacity, 0)
+	Equal(t, st.Evictions, uint64(0))
+}
+
+func TestCacheCapacity(t *testing.T) {
+	type Test struct {
+		String string `mold:"trim"`
+	}
+
+	set := New()
+	set.Register("trim", func(ctx context.Context, fl FieldLevel) error {
+		fl.Field().SetString(strings.TrimSpace(fl.Field().String()))
+		return nil
+	})
+
+	tags, structs := set.CacheStats()
+	Equal(t, tags.Capacity, DefaultTagCacheCapacity)
+	Equal(t, structs.Capacity, 0)
+
+	set.SetCacheCapacity(16, 1)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				s := " a "
+				// alternate between a common and distinct tags
+				tags := "trim"
+				if j%2 == 0 {
+					tags += strings.Repeat(",trim", (i*100+j)%40+1)
+				}
+
+				err := set.Field(context.Background(), &s, tags)
+				Equal(t, err, nil)
+				Equal(t, s, "a")
+
+				tt := Test{String: " a "}
+				err = set.Struct(context.Background(), &tt)
+				Equal(t, err, nil)
+				Equal(t, tt.String, "a")
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	tags, structs = set.CacheStats()
+	Equal(t, tags.Len <= 16, true)
+	Equal(t, tags.Evictions > 0, true)
+	Equal(t, tags.Hits > 0, true)
+	Equal(t, tags.Misses > 0, true)
+	Equal(t, structs.Len, 1)
+	Equal(t, structs.Hits > 0, true)
+
+	PanicMatches(t, func() { set.SetCacheCapacity(-1, 0) }, "cache capacity cannot be negative")
+}
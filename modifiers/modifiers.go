@@ -1,34 +1,41 @@
 package modifiers
 
 import (
+	"reflect"
+
 	"github.com/go-playground/mold/v4"
 )
 
+// stringOpts declares the string modifiers only accept strings and byte slices eg. []byte and json.RawMessage, and
+// are applied to each element of slices, arrays and maps of them.
+var stringOpts = []mold.RegisterOption{mold.Kinds(reflect.String), mold.Bytes(), mold.ElementWise()}
+
 // New returns a modifier with defaults registered
 func New() *mold.Transformer {
 	mod := mold.New()
-	mod.Register("camel", camelCase)
+	mod.Register("camel", camelCase, stringOpts...)
 	mod.Register("default", defaultValue)
 	mod.Register("empty", empty)
-	mod.Register("lcase", toLower)
-	mod.Register("ltrim", trimLeft)
-	mod.Register("name", nameCase)
-	mod.Register("rtrim", trimRight)
+	mod.Register("lcase", toLower, stringOpts...)
+	mod.Register("ltrim", trimLeft, stringOpts...)
+	mod.Register("name", nameCase, stringOpts...)
+	mod.Register("rtrim", trimRight, stringOpts...)
 	mod.Register("set", setValue)
-	mod.Register("snake", snakeCase)
-	mod.Register("slug", slugCase)
-	mod.Register("strip_alpha_unicode", stripAlphaUnicodeCase)
-	mod.Register("strip_alpha", stripAlphaCase)
-	mod.Register("strip_num_unicode", stripNumUnicodeCase)
-	mod.Register("strip_num", stripNumCase)
-	mod.Register("strip_punctuation", stripPunctuation)
-	mod.Register("substr", subStr)
-	mod.Register("title", titleCase)
-	mod.Register("tprefix", trimPrefix)
-	mod.Register("trim", trimSpace)
-	mod.Register("tsuffix", trimSuffix)
-	mod.Register("ucase", toUpper)
-	mod.Register("ucfirst", uppercaseFirstCharacterCase)
+	mod.Register("snake", snakeCase, stringOpts...)
+	mod.Register("slug", slugCase, stringOpts...)
+	mod.Register("strip_alpha_unicode", stripAlphaUnicodeCase, stringOpts...)
+	mod.Register("strip_alpha", stripAlphaCase, stringOpts...)
+	mod.Register("strip_num_unicode", stripNumUnicodeCase, stringOpts...)
+	mod.Register("strip_num", stripNumCase, stringOpts...)
+	mod.Register("strip_punctuation", stripPunctuation, stringOpts...)
+	mod.Register("substr", subStr, stringOpts...)
+	mod.Register("title", titleCase, stringOpts...)
+	mod.Register("tprefix", trimPrefix, stringOpts...)
+	mod.Register("trim", trimSpace, stringOpts...)
+	mod.Register("tsuffix", trimSuffix, stringOpts...)
+	mod.Register("ucase", toUpper, stringOpts...)
+	mod.Register("ucfirst", uppercaseFirstCharacterCase, stringOpts...)
+	mod.RegisterSQLInterceptors()
 	mod.SetTagName("mod")
 	return mod
 }
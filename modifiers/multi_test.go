@@ -2,6 +2,7 @@ package modifiers
 
 import (
 	"context"
+	"database/sql"
 	"testing"
 	"time"
 
@@ -577,6 +578,33 @@ func TestEmpty(t *testing.T) {
 	}
 }
 
+func TestSQLNullTypes(t *testing.T) {
+	type Test struct {
+		Default sql.NullString  `mod:"default=foo"`
+		Empty   sql.NullString  `mod:"empty"`
+		Trim    sql.NullString  `mod:"trim"`
+		Int     sql.NullInt64   `mod:"set=5"`
+		Bool    sql.NullBool    `mod:"default=true"`
+		Float   sql.NullFloat64 `mod:"default=1.5"`
+	}
+
+	conform := New()
+
+	tt := Test{
+		Empty: sql.NullString{String: "value", Valid: true},
+		Trim:  sql.NullString{String: " value ", Valid: true},
+	}
+
+	err := conform.Struct(context.Background(), &tt)
+	Equal(t, err, nil)
+	Equal(t, tt.Default, sql.NullString{String: "foo", Valid: true})
+	Equal(t, tt.Empty, sql.NullString{})
+	Equal(t, tt.Trim, sql.NullString{String: "value", Valid: true})
+	Equal(t, tt.Int, sql.NullInt64{Int64: 5, Valid: true})
+	Equal(t, tt.Bool, sql.NullBool{Bool: true, Valid: true})
+	Equal(t, tt.Float, sql.NullFloat64{Float64: 1.5, Valid: true})
+}
+
 func newPointer[T any](value T) *T {
 	return &value
 }
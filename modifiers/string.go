@@ -3,7 +3,6 @@ package modifiers
 import (
 	"bytes"
 	"context"
-	"reflect"
 	"regexp"
 	"strconv"
 	"strings"
@@ -20,94 +19,54 @@ import (
 )
 
 // trimSpace trims extra space from text
-func trimSpace(ctx context.Context, fl mold.FieldLevel) error {
-	switch fl.Field().Kind() {
-	case reflect.String:
-		fl.Field().SetString(strings.TrimSpace(fl.Field().String()))
-	}
-	return nil
-}
+var trimSpace = mold.StringFunc(func(_ context.Context, s string) (string, error) {
+	return strings.TrimSpace(s), nil
+})
 
 // trimLeft trims extra left hand side of string using provided cutset
-func trimLeft(ctx context.Context, fl mold.FieldLevel) error {
-	switch fl.Field().Kind() {
-	case reflect.String:
-		fl.Field().SetString(strings.TrimLeft(fl.Field().String(), fl.Param()))
-	}
-	return nil
-}
+var trimLeft = mold.StringParamFunc(func(_ context.Context, s, param string) (string, error) {
+	return strings.TrimLeft(s, param), nil
+})
 
 // trimRight trims extra right hand side of string using provided cutset
-func trimRight(ctx context.Context, fl mold.FieldLevel) error {
-	switch fl.Field().Kind() {
-	case reflect.String:
-		fl.Field().SetString(strings.TrimRight(fl.Field().String(), fl.Param()))
-	}
-	return nil
-}
+var trimRight = mold.StringParamFunc(func(_ context.Context, s, param string) (string, error) {
+	return strings.TrimRight(s, param), nil
+})
 
 // trimPrefix trims the string of a prefix
-func trimPrefix(ctx context.Context, fl mold.FieldLevel) error {
-	switch fl.Field().Kind() {
-	case reflect.String:
-		fl.Field().SetString(strings.TrimPrefix(fl.Field().String(), fl.Param()))
-	}
-	return nil
-}
+var trimPrefix = mold.StringParamFunc(func(_ context.Context, s, param string) (string, error) {
+	return strings.TrimPrefix(s, param), nil
+})
 
 // trimSuffix trims the string of a suffix
-func trimSuffix(ctx context.Context, fl mold.FieldLevel) error {
-	switch fl.Field().Kind() {
-	case reflect.String:
-		fl.Field().SetString(strings.TrimSuffix(fl.Field().String(), fl.Param()))
-	}
-	return nil
-}
+var trimSuffix = mold.StringParamFunc(func(_ context.Context, s, param string) (string, error) {
+	return strings.TrimSuffix(s, param), nil
+})
 
 // toLower convert string to lower case
-func toLower(ctx context.Context, fl mold.FieldLevel) error {
-	switch fl.Field().Kind() {
-	case reflect.String:
-		fl.Field().SetString(strings.ToLower(fl.Field().String()))
-	}
-	return nil
-}
+var toLower = mold.StringFunc(func(_ context.Context, s string) (string, error) {
+	return strings.ToLower(s), nil
+})
 
 // toUpper convert string to upper case
-func toUpper(ctx context.Context, fl mold.FieldLevel) error {
-	switch fl.Field().Kind() {
-	case reflect.String:
-		fl.Field().SetString(strings.ToUpper(fl.Field().String()))
-	}
-	return nil
-}
+var toUpper = mold.StringFunc(func(_ context.Context, s string) (string, error) {
+	return strings.ToUpper(s), nil
+})
 
 // snakeCase converts string to snake case
-func snakeCase(ctx context.Context, fl mold.FieldLevel) error {
-	switch fl.Field().Kind() {
-	case reflect.String:
-		fl.Field().SetString(snakecase.Snakecase(fl.Field().String()))
-	}
-	return nil
-}
+var snakeCase = mold.StringFunc(func(_ context.Context, s string) (string, error) {
+	return snakecase.Snakecase(s), nil
+})
 
 // slug converts string to a slug
-func slugCase(ctx context.Context, fl mold.FieldLevel) error {
-	switch fl.Field().Kind() {
-	case reflect.String:
-		fl.Field().SetString(slug.Make(fl.Field().String()))
-	}
-	return nil
-}
+var slugCase = mold.StringFunc(func(_ context.Context, s string) (string, error) {
+	return slug.Make(s), nil
+})
 
 // titleCase converts string to title case, e.g. "this is a sentence" -> "This Is A Sentence"
-func titleCase(ctx context.Context, fl mold.FieldLevel) error {
-	switch fl.Field().Kind() {
-	case reflect.String:
-		fl.Field().SetString(cases.Title(language.Und, cases.NoLower).String(fl.Field().String()))
-	}
-	return nil
-}
+var titleCase = mold.StringFunc(func(_ context.Context, s string) (string, error) {
+	return cases.Title(language.Und, cases.NoLower).String(s), nil
+})
 
 var namePatterns = []map[string]string{
 	{`[^\pL-\s']`: ""}, // cut off everything except [ alpha, hyphen, whitespace, apostrophe]
@@ -123,13 +82,9 @@ var nameRegex = regexp.MustCompile(`[\p{L}]([\p{L}|[:space:]\-']*[\p{L}])*`)
 // converts multiple spaces and dashes to single characters, title cases multiple names.
 // Example: "3493€848Jo-$%£@Ann " -> "Jo-Ann", " ~~ The Dude ~~" -> "The Dude", "**susan**" -> "Susan",
 // " hugh fearnley-whittingstall" -> "Hugh Fearnley-Whittingstall"
-func nameCase(ctx context.Context, fl mold.FieldLevel) error {
-	switch fl.Field().Kind() {
-	case reflect.String:
-		fl.Field().SetString(cases.Title(language.Und, cases.NoLower).String(nameRegex.FindString(onlyOne(strings.ToLower(fl.Field().String())))))
-	}
-	return nil
-}
+var nameCase = mold.StringFunc(func(_ context.Context, s string) (string, error) {
+	return cases.Title(language.Und, cases.NoLower).String(nameRegex.FindString(onlyOne(strings.ToLower(s)))), nil
+})
 
 func onlyOne(s string) string {
 	for _, v := range namePatterns {
@@ -141,125 +96,89 @@ func onlyOne(s string) string {
 }
 
 // uppercaseFirstCharacterCase converts a string so that it has only the first capital letter. Example: "all lower" -> "All lower"
-func uppercaseFirstCharacterCase(_ context.Context, fl mold.FieldLevel) error {
-	switch fl.Field().Kind() {
-	case reflect.String:
-		s := fl.Field().String()
-		if s == "" {
-			return nil
-		}
+var uppercaseFirstCharacterCase = mold.StringFunc(func(_ context.Context, s string) (string, error) {
+	if s == "" {
+		return s, nil
+	}
 
-		toRune, size := utf8.DecodeRuneInString(s)
-		if !unicode.IsLower(toRune) {
-			return nil
-		}
-		buf := &bytes.Buffer{}
-		buf.WriteRune(unicode.ToUpper(toRune))
-		buf.WriteString(s[size:])
-		fl.Field().SetString(buf.String())
+	toRune, size := utf8.DecodeRuneInString(s)
+	if !unicode.IsLower(toRune) {
+		return s, nil
 	}
-	return nil
-}
+	buf := &bytes.Buffer{}
+	buf.WriteRune(unicode.ToUpper(toRune))
+	buf.WriteString(s[size:])
+	return buf.String(), nil
+})
 
 var stripNumRegex = regexp.MustCompile("[^0-9]")
 
 // stripAlphaCase removes all non-numeric characters. Example: "the price is €30,38" -> "3038". Note: The struct field will remain a string. No type conversion takes place.
-func stripAlphaCase(_ context.Context, fl mold.FieldLevel) error {
-	switch fl.Field().Kind() {
-	case reflect.String:
-		fl.Field().SetString(stripNumRegex.ReplaceAllLiteralString(fl.Field().String(), ""))
-	}
-	return nil
-}
+var stripAlphaCase = mold.StringFunc(func(_ context.Context, s string) (string, error) {
+	return stripNumRegex.ReplaceAllLiteralString(s, ""), nil
+})
 
 var stripAlphaRegex = regexp.MustCompile("[0-9]")
 
 // stripNumCase removes all numbers. Example "39472349D34a34v69e8932747" -> "Dave". Note: The struct field will remain a string. No type conversion takes place.
-func stripNumCase(_ context.Context, fl mold.FieldLevel) error {
-	switch fl.Field().Kind() {
-	case reflect.String:
-		fl.Field().SetString(stripAlphaRegex.ReplaceAllLiteralString(fl.Field().String(), ""))
-	}
-	return nil
-}
+var stripNumCase = mold.StringFunc(func(_ context.Context, s string) (string, error) {
+	return stripAlphaRegex.ReplaceAllLiteralString(s, ""), nil
+})
 
 var stripNumUnicodeRegex = regexp.MustCompile(`[^\pL]`)
 
 // stripNumUnicodeCase removes non-alpha unicode characters. Example: "!@£$%^&'()Hello 1234567890 World+[];\" -> "HelloWorld"
-func stripNumUnicodeCase(ctx context.Context, fl mold.FieldLevel) error {
-	switch fl.Field().Kind() {
-	case reflect.String:
-		fl.Field().SetString(stripNumUnicodeRegex.ReplaceAllLiteralString(fl.Field().String(), ""))
-	}
-	return nil
-}
+var stripNumUnicodeCase = mold.StringFunc(func(_ context.Context, s string) (string, error) {
+	return stripNumUnicodeRegex.ReplaceAllLiteralString(s, ""), nil
+})
 
 var stripAlphaUnicode = regexp.MustCompile(`[\pL]`)
 
 // stripAlphaUnicodeCase removes alpha unicode characters. Example: "Everything's here but the letters!" -> "' !"
-func stripAlphaUnicodeCase(ctx context.Context, fl mold.FieldLevel) error {
-	switch fl.Field().Kind() {
-	case reflect.String:
-		fl.Field().SetString(stripAlphaUnicode.ReplaceAllLiteralString(fl.Field().String(), ""))
-	}
-	return nil
-}
+var stripAlphaUnicodeCase = mold.StringFunc(func(_ context.Context, s string) (string, error) {
+	return stripAlphaUnicode.ReplaceAllLiteralString(s, ""), nil
+})
 
 var stripPunctuationRegex = regexp.MustCompile(`[[:punct:]]`)
 
 // stripPunctuation removes punctuation. Example: "# M5W-1E6!!!" -> " M5W1E6"
-func stripPunctuation(ctx context.Context, fl mold.FieldLevel) error {
-	switch fl.Field().Kind() {
-	case reflect.String:
-		fl.Field().SetString(stripPunctuationRegex.ReplaceAllLiteralString(fl.Field().String(), ""))
-	}
-	return nil
-}
+var stripPunctuation = mold.StringFunc(func(_ context.Context, s string) (string, error) {
+	return stripPunctuationRegex.ReplaceAllLiteralString(s, ""), nil
+})
 
 // camelCase converts string to camel case
-func camelCase(ctx context.Context, fl mold.FieldLevel) error {
-	switch fl.Field().Kind() {
-	case reflect.String:
-		fl.Field().SetString(camelcase.Camelcase(fl.Field().String()))
+var camelCase = mold.StringFunc(func(_ context.Context, s string) (string, error) {
+	return camelcase.Camelcase(s), nil
+})
+
+// subStr returns the substring between the start and optional end indexes of the param eg. substr=1-3
+var subStr = mold.StringParamFunc(func(_ context.Context, val, param string) (string, error) {
+	params := strings.SplitN(param, "-", 2)
+	if len(params) == 0 || len(params[0]) == 0 {
+		return val, nil
 	}
-	return nil
-}
 
-func subStr(ctx context.Context, fl mold.FieldLevel) error {
-	switch fl.Field().Kind() {
-	case reflect.String:
-		val := fl.Field().String()
-		params := strings.SplitN(fl.Param(), "-", 2)
-		if len(params) == 0 || len(params[0]) == 0 {
-			return nil
-		}
+	start, err := strconv.Atoi(params[0])
+	if err != nil {
+		return val, err
+	}
 
-		start, err := strconv.Atoi(params[0])
+	end := len(val)
+	if len(params) >= 2 {
+		end, err = strconv.Atoi(params[1])
 		if err != nil {
-			return err
-		}
-
-		end := len(val)
-		if len(params) >= 2 {
-			end, err = strconv.Atoi(params[1])
-			if err != nil {
-				return err
-			}
-		}
-
-		if len(val) < start {
-			fl.Field().SetString("")
-			return nil
-		}
-		if len(val) < end {
-			end = len(val)
-		}
-		if start > end {
-			fl.Field().SetString("")
-			return nil
+			return val, err
 		}
+	}
 
-		fl.Field().SetString(val[start:end])
+	if len(val) < start {
+		return "", nil
 	}
-	return nil
-}
+	if len(val) < end {
+		end = len(val)
+	}
+	if start > end {
+		return "", nil
+	}
+	return val[start:end], nil
+})
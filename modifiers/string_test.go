@@ -3,6 +3,7 @@ package modifiers
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"log"
 	"reflect"
 	"testing"
@@ -1100,3 +1101,88 @@ func TestSubStr(t *testing.T) {
 		}
 	}
 }
+
+func TestStringModifiersStrict(t *testing.T) {
+	type Test struct {
+		Name  *string `mod:"trim"`
+		Count int     `mod:"default=5"`
+		Code  int     `mod:"ucase"`
+	}
+
+	conform := New()
+
+	tt := Test{Code: 1}
+	err := conform.Struct(context.Background(), &tt)
+	require.NoError(t, err)
+	require.Equal(t, 5, tt.Count)
+
+	conform.SetStrict(true)
+
+	err = conform.Struct(context.Background(), &tt)
+	require.EqualError(t, err, "mold: 'ucase' cannot be applied to int at 'Test.Code'")
+
+	var i int
+	err = conform.Field(context.Background(), &i, "trim")
+	require.EqualError(t, err, "mold: 'trim' cannot be applied to int at ''")
+
+	ns := sql.NullString{String: " a ", Valid: true}
+	err = conform.Field(context.Background(), &ns, "trim")
+	require.NoError(t, err)
+	require.Equal(t, "a", ns.String)
+}
+
+func TestStringModifiersElementWise(t *testing.T) {
+	type Test struct {
+		Tags   []string          `mod:"trim,lcase"`
+		Labels map[string]string `mod:"ucase"`
+		Codes  *[2]string        `mod:"tprefix=x"`
+	}
+
+	conform := New()
+
+	tt := Test{
+		Tags:   []string{" A ", "b "},
+		Labels: map[string]string{"k": "v"},
+		Codes:  &[2]string{"xa", "b"},
+	}
+	err := conform.Struct(context.Background(), &tt)
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b"}, tt.Tags)
+	require.Equal(t, map[string]string{"k": "V"}, tt.Labels)
+	require.Equal(t, [2]string{"a", "b"}, *tt.Codes)
+}
+
+func TestStringModifiersBytes(t *testing.T) {
+	type Test struct {
+		Bytes  []byte          `mod:"trim,ucase"`
+		Raw    json.RawMessage `mod:"trim"`
+		Nil    []byte          `mod:"trim"`
+		Empty  []byte          `mod:"trim"`
+		Spaces []byte          `mod:"trim"`
+		Many   [][]byte        `mod:"lcase"`
+	}
+
+	conform := New()
+
+	tt := Test{
+		Bytes:  []byte(" abc "),
+		Raw:    json.RawMessage(" {\"a\":1} "),
+		Empty:  []byte{},
+		Spaces: []byte("   "),
+		Many:   [][]byte{[]byte("A"), nil},
+	}
+	err := conform.Struct(context.Background(), &tt)
+	require.NoError(t, err)
+	require.Equal(t, []byte("ABC"), tt.Bytes)
+	require.Equal(t, json.RawMessage(`{"a":1}`), tt.Raw)
+	require.Nil(t, tt.Nil)
+	require.NotNil(t, tt.Empty)
+	require.Len(t, tt.Empty, 0)
+	require.NotNil(t, tt.Spaces)
+	require.Len(t, tt.Spaces, 0)
+	require.Equal(t, [][]byte{[]byte("a"), nil}, tt.Many)
+
+	conform.SetStrict(true)
+	err = conform.Struct(context.Background(), &tt)
+	require.NoError(t, err)
+}
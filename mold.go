@@ -4,12 +4,17 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"runtime/debug"
 	"strings"
+	"sync/atomic"
 	"time"
+	"unsafe"
 )
 
 var (
 	timeType           = reflect.TypeOf(time.Time{})
+	pairKeyField       = "Key"
+	pairValueField     = "Value"
 	restrictedAliasErr = "Alias '%s' either contains restricted characters or is the same as a restricted tag needed for normal operation"
 	restrictedTagErr   = "Tag '%s' either contains restricted characters or is the same as a restricted tag needed for normal operation"
 )
@@ -23,46 +28,78 @@ type Transform interface {
 }
 
 // Func defines a transform function for use.
+//
+// The FieldLevel is reused once the function returns and so must not be retained.
 type Func func(ctx context.Context, fl FieldLevel) error
 
+// MiddlewareFunc wraps the Func registered against the tag name, returning the Func to use in its place.
+type MiddlewareFunc func(name string, next Func) Func
+
 // StructLevelFunc accepts all values needed for struct level manipulation.
 //
 // Why does this exist? For structs for which you may not have access or rights to add tags too,
 // from other packages your using.
+//
+// The StructLevel is reused once the function returns and so must not be retained.
 type StructLevelFunc func(ctx context.Context, sl StructLevel) error
 
 // InterceptorFunc is a way to intercept custom types to redirect the functions to be applied to an inner typ/value.
 // eg. sql.NullString, the manipulation should be done on the inner string.
+// An invalid reflect.Value may be returned to decline intercepting the current value.
 type InterceptorFunc func(current reflect.Value) (inner reflect.Value)
 
+// WriteBackFunc is called after each transformation applied to the inner value returned by an InterceptorFunc,
+// allowing the state of the intercepted custom type to be kept consistent with the inner value.
+// eg. the Valid field of sql.NullString.
+type WriteBackFunc func(current, inner reflect.Value) error
+
+type interceptor struct {
+	fn        InterceptorFunc
+	writeBack WriteBackFunc
+}
+
 // Transformer is the base controlling object which contains
 // all necessary information
 type Transformer struct {
-	tagName          string
-	aliases          map[string]string
-	transformations  map[string]Func
-	structLevelFuncs map[reflect.Type]StructLevelFunc
-	interceptors     map[reflect.Type]InterceptorFunc
-	cCache           *structCache
-	tCache           *tagCache
+	tagName           string
+	includeUnexported bool
+	aliases           map[string]string
+	transformations   map[string]Func
+	structLevelFuncs  map[reflect.Type]StructLevelFunc
+	structTags        map[reflect.Type]map[string]string
+	config            atomic.Value // *configRules
+	interceptors      map[reflect.Type]*interceptor
+	matchInterceptors []matchInterceptor
+	workers           int
+	minParallelLen    int
+	keyCollision      KeyCollisionPolicy
+	keyMerge          KeyMergeFunc
+	hooks             []Hook
+	middleware        []MiddlewareFunc
+	recoverPanics     bool
+	strict            bool
+	acceptances       map[string]*acceptance
+	iCache            *interceptorCache
+	cCache            *structCache
+	tCache            *tagCache
 }
 
 // New creates a new Transform object with default tag name of 'mold'
 func New() *Transformer {
-	tc := new(tagCache)
-	tc.m.Store(make(map[string]*cTag))
-
-	sc := new(structCache)
-	sc.m.Store(make(map[reflect.Type]*cStruct))
+	ic := new(interceptorCache)
+	ic.m.Store(make(map[reflect.Type]*interceptor))
 
-	return &Transformer{
+	t := &Transformer{
 		tagName:         "mold",
+		workers:         1,
 		aliases:         make(map[string]string),
 		transformations: make(map[string]Func),
-		interceptors:    make(map[reflect.Type]InterceptorFunc),
-		cCache:          sc,
-		tCache:          tc,
+		interceptors:    make(map[reflect.Type]*interceptor),
+		iCache:          ic,
 	}
+	t.config.Store(new(configRules))
+	t.SetCacheCapacity(DefaultTagCacheCapacity, 0)
+	return t
 }
 
 // SetTagName sets the given tag name to be used.
@@ -71,12 +108,34 @@ func (t *Transformer) SetTagName(tagName string) {
 	t.tagName = tagName
 }
 
-// Register adds a transformation with the given tag
+// SetIncludeUnexported sets whether unexported struct fields are transformed, default is false.
+// This allows tags to be used on unexported fields, of structs within the same package, for those with
+// strict encapsulation requirements.
+//
+// NOTES:
+// - unexported fields are accessed using the unsafe package, bypassing the usual reflect protections.
+// - this method is not thread-safe it is intended that this be set prior to any transformation
+func (t *Transformer) SetIncludeUnexported(include bool) {
+	t.includeUnexported = include
+	t.resetCaches()
+}
+
+// SetRecoverPanics sets whether panics occurring while transforming, such as a Func calling SetString on a
+// non-string field, are recovered and returned as an *ErrPanic rather than propagated, default is false.
+//
+// NOTES:
+// - this method is not thread-safe it is intended that this be set prior to any transformation
+func (t *Transformer) SetRecoverPanics(recoverPanics bool) {
+	t.recoverPanics = recoverPanics
+}
+
+// Register adds a transformation with the given tag, the RegisterOptions optionally declaring the
+// kinds or types of values it accepts eg. Register("trim", trim, Kinds(reflect.String)).
 //
 // NOTES:
 // - if the key already exists, the previous transformation function will be replaced.
 // - this method is not thread-safe it is intended that these all be registered before hand
-func (t *Transformer) Register(tag string, fn Func) {
+func (t *Transformer) Register(tag string, fn Func, opts ...RegisterOption) {
 	if len(tag) == 0 {
 		panic("Function Key cannot be empty")
 	}
@@ -91,6 +150,55 @@ func (t *Transformer) Register(tag string, fn Func) {
 		panic(fmt.Sprintf(restrictedTagErr, tag))
 	}
 	t.transformations[tag] = fn
+
+	if len(opts) == 0 {
+		delete(t.acceptances, tag)
+		return
+	}
+
+	a := new(acceptance)
+	for _, opt := range opts {
+		opt(a)
+	}
+	if t.acceptances == nil {
+		t.acceptances = make(map[string]*acceptance)
+	}
+	t.acceptances[tag] = a
+}
+
+// Use adds middleware which wraps every registered Func, both those already registered and those registered
+// afterwards, centralizing cross-cutting behaviour such as logging or skipping certain values. The name passed
+// to the middleware is the tag the Func is registered against.
+//
+// The first middleware added is the outermost, being called first.
+//
+// eg.
+//
+//	t.Use(func(name string, next mold.Func) mold.Func {
+//		return func(ctx context.Context, fl mold.FieldLevel) error {
+//			log.Printf("applying %s to %s", name, fl.Namespace())
+//			return next(ctx, fl)
+//		}
+//	})
+//
+// NOTES:
+// - this method is not thread-safe it is intended that these all be added prior to any transformation
+func (t *Transformer) Use(middleware ...MiddlewareFunc) {
+	for _, mw := range middleware {
+		if mw == nil {
+			panic("Middleware cannot be empty")
+		}
+		t.middleware = append(t.middleware, mw)
+	}
+	t.resetCaches()
+}
+
+// wrap returns the Func registered against the tag wrapped by the middleware.
+func (t *Transformer) wrap(tag string, fn Func) Func {
+	for i := len(t.middleware) - 1; i >= 0; i-- {
+		fn = t.middleware[i](tag, fn)
+	}
+	return fn
 }
 
 // RegisterAlias registers a mapping of a single transform tag that
@@ -129,6 +237,45 @@ func (t *Transformer) RegisterStructLevel(fn StructLevelFunc, types ...interface
 	for _, typ := range types {
 		t.structLevelFuncs[reflect.TypeOf(typ)] = fn
 	}
+	t.resetCaches()
+}
+
+// RegisterStructTags registers tags against the fields of a struct type by field name, as if they
+// were defined on the struct itself. A registered tag takes precedence over one defined on the field.
+// Why does this exist? For structs for which you may not have access or rights to add tags too,
+// from other packages your using, eg. generated protobuf types.
+//
+// eg. RegisterStructTags(pb.User{}, map[string]string{"Name": "trim", "Email": "trim,lcase"})
+//
+// Tags can also be registered against fields promoted from embedded structs, replacing the tags of the embedded struct's
+// field only when transformed as part of this struct.
+//
+// NOTES:
+// - registering tags against the same type again replaces the previously registered tags.
+// - this method is not thread-safe it is intended that these all be registered prior to any transformation
+func (t *Transformer) RegisterStructTags(typ interface{}, tags map[string]string) {
+	rt := reflect.TypeOf(typ)
+	for rt != nil && rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+	}
+
+	if rt == nil || rt.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("RegisterStructTags requires a struct type, %v provided", rt))
+	}
+
+	m := make(map[string]string, len(tags))
+	for name, tag := range tags {
+		if fld, ok := rt.FieldByName(name); !ok || (!fld.Anonymous && len(fld.PkgPath) > 0) {
+			panic(fmt.Sprintf("Field '%s' does not exist or is not exported on type %s", name, rt))
+		}
+		m[name] = tag
+	}
+
+	if t.structTags == nil {
+		t.structTags = make(map[reflect.Type]map[string]string)
+	}
+	t.structTags[rt] = m
+	t.resetCaches()
 }
 
 // RegisterInterceptor registers a new interceptor functions agains one or more types.
@@ -138,8 +285,41 @@ func (t *Transformer) RegisterStructLevel(fn StructLevelFunc, types ...interface
 // eg. sql.NullString
 func (t *Transformer) RegisterInterceptor(fn InterceptorFunc, types ...interface{}) {
 	for _, typ := range types {
-		t.interceptors[reflect.TypeOf(typ)] = fn
+		t.interceptors[reflect.TypeOf(typ)] = &interceptor{fn: fn}
+	}
+	t.resetCaches()
+}
+
+// RegisterWriteBackInterceptor registers a new interceptor function, along with a WriteBackFunc, against one or more types.
+// The WriteBackFunc is called after each transformation is applied to the inner value returned by the InterceptorFunc
+// to update the custom type accordingly.
+//
+// eg. sql.NullString, setting Valid based on whether the inner string has a value.
+func (t *Transformer) RegisterWriteBackInterceptor(fn InterceptorFunc, writeBack WriteBackFunc, types ...interface{}) {
+	for _, typ := range types {
+		t.interceptors[reflect.TypeOf(typ)] = &interceptor{fn: fn, writeBack: writeBack}
+	}
+	t.resetCaches()
+}
+
+// alias returns the tags registered for the provided alias, aliases of the Config rules take precedence.
+func (t *Transformer) alias(rules *configRules, alias string) (tags string, found bool) {
+	if tags, found = rules.aliases[alias]; !found {
+		tags, found = t.aliases[alias]
 	}
+	return
+}
+
+// resetCaches discards all cached struct and tag information so that it is rebuilt using the
+// currently registered rules.
+func (t *Transformer) resetCaches() {
+	t.cCache.lock.Lock()
+	t.cCache.reset()
+	t.cCache.lock.Unlock()
+
+	t.tCache.lock.Lock()
+	t.tCache.reset()
+	t.tCache.lock.Unlock()
 }
 
 // Struct applies transformations against the provided struct
@@ -156,33 +336,83 @@ func (t *Transformer) Struct(ctx context.Context, v interface{}) error {
 	if val.Kind() != reflect.Struct || val.Type() == timeType {
 		return &ErrInvalidTransformation{typ: reflect.TypeOf(v)}
 	}
-	return t.setByStruct(ctx, orig, val, typ)
+	loc := location{top: orig, name: typ.Name()}
+
+	if len(t.hooks) > 0 {
+		return t.observeStruct(ctx, loc.name, func(ctx context.Context) error {
+			return t.setByStruct(ctx, loc, orig, val, typ)
+		})
+	}
+	return t.setByStruct(ctx, loc, orig, val, typ)
 }
 
-func (t *Transformer) setByStruct(ctx context.Context, parent, current reflect.Value, typ reflect.Type) (err error) {
-	cs, ok := t.cCache.Get(typ)
+func (t *Transformer) setByStruct(ctx context.Context, loc location, parent, current reflect.Value, typ reflect.Type) (err error) {
+	var cs *cStruct
+	var ok bool
+
+	// embedded structs with promoted tags are compiled specifically for the field
+	if loc.fld != nil && loc.fld.embedded != nil && loc.fld.embedded.typ == typ {
+		cs, ok = loc.fld.embedded, true
+	} else {
+		cs, ok = t.cCache.Get(typ)
+	}
+
 	if !ok {
 		if cs, err = t.extractStructCache(current); err != nil {
 			return
 		}
 	}
+	return t.setByStructCached(ctx, loc, parent, current, cs)
+}
+
+func (t *Transformer) setByStructCached(ctx context.Context, loc location, parent, current reflect.Value, cs *cStruct) (err error) {
+	if t.recoverPanics {
+		defer recoverPanic(&err, loc, "")
+	}
 
 	// run is struct has a corresponding struct level transformation
 	if cs.fn != nil {
-		if err = cs.fn(ctx, structLevel{
+		sl := structLevelPool.Get().(*structLevel)
+		*sl = structLevel{
 			transformer: t,
 			parent:      parent,
 			current:     current,
-		}); err != nil {
+			loc:         loc,
+		}
+
+		err = cs.fn(ctx, sl)
+
+		*sl = structLevel{}
+		structLevelPool.Put(sl)
+
+		if err != nil {
 			return
 		}
 	}
 
 	var f *cField
 
+	// the struct location is referenced by each of its fields
+	structLoc := loc.push()
+	defer structLoc.release()
+
 	for i := 0; i < len(cs.fields); i++ {
 		f = cs.fields[i]
-		if err = t.setByField(ctx, current.Field(f.idx), f.cTags); err != nil {
+
+		fieldLoc := structLoc.field(current, f)
+		if err = contextDone(ctx, fieldLoc); err != nil {
+			return
+		}
+
+		fv := current.Field(f.idx)
+		if f.unexported {
+			if !fv.CanAddr() {
+				continue
+			}
+			fv = reflect.NewAt(fv.Type(), unsafe.Pointer(fv.UnsafeAddr())).Elem()
+		}
+
+		if err = t.setByField(ctx, fieldLoc, fv, f.cTags); err != nil {
 			return
 		}
 	}
@@ -195,12 +425,12 @@ func (t *Transformer) Field(ctx context.Context, v interface{}, tags string) (er
 		return nil
 	}
 
-	val := reflect.ValueOf(v)
+	orig := reflect.ValueOf(v)
 
-	if val.Kind() != reflect.Ptr || val.IsNil() {
+	if orig.Kind() != reflect.Ptr || orig.IsNil() {
 		return &ErrInvalidTransformValue{typ: reflect.TypeOf(v), fn: "Field"}
 	}
-	val = val.Elem()
+	val := orig.Elem()
 
 	// find cached tag
 	ctag, ok := t.tCache.Get(tags)
@@ -211,7 +441,7 @@ func (t *Transformer) Field(ctx context.Context, v interface{}, tags string) (er
 		// isn't parsed again.
 		ctag, ok = t.tCache.Get(tags)
 		if !ok {
-			if ctag, _, err = t.parseFieldTagsRecursive(tags, "", "", false); err != nil {
+			if ctag, _, err = t.parseFieldTagsRecursive(t.rules(), tags, "", "", false); err != nil {
 				t.tCache.lock.Unlock()
 				return
 			}
@@ -219,26 +449,31 @@ func (t *Transformer) Field(ctx context.Context, v interface{}, tags string) (er
 		}
 		t.tCache.lock.Unlock()
 	}
-	err = t.setByField(ctx, val, ctag)
+	err = t.setByField(ctx, location{top: orig}, val, ctag)
 	return
 }
 
-func (t *Transformer) setByField(ctx context.Context, orig reflect.Value, ct *cTag) (err error) {
-	current, kind := t.extractType(orig)
+func (t *Transformer) setByField(ctx context.Context, loc location, orig reflect.Value, ct *cTag) (err error) {
+	if t.recoverPanics {
+		defer recoverPanic(&err, loc, "")
+	}
+
+	current, kind, wbs := t.extractTypeWriteBack(orig, nil)
 
 	if ct != nil && ct.hasTag {
 		for ct != nil {
 			switch ct.typeof {
 			case typeEndKeys:
-				return
+				// continue on to traverse struct keys
+				ct = nil
 			case typeDive:
 				ct = ct.next
 
 				switch kind {
 				case reflect.Slice, reflect.Array:
-					err = t.setByIterable(ctx, current, ct)
+					err = t.setByIterable(ctx, loc, current, ct)
 				case reflect.Map:
-					err = t.setByMap(ctx, current, ct)
+					err = t.setByMap(ctx, loc, current, ct)
 				case reflect.Ptr:
 					innerKind := current.Type().Elem().Kind()
 					if innerKind == reflect.Slice || innerKind == reflect.Map {
@@ -256,27 +491,20 @@ func (t *Transformer) setByField(ctx context.Context, orig reflect.Value, ct *cT
 				if !current.CanAddr() {
 					newVal := reflect.New(current.Type()).Elem()
 					newVal.Set(current)
-					if err = ct.fn(ctx, fieldLevel{
-						transformer: t,
-						parent:      orig,
-						current:     newVal,
-						param:       ct.param,
-					}); err != nil {
+					if err = t.call(ctx, loc, ct, orig, newVal); err != nil {
 						return
 					}
 					orig.Set(reflect.Indirect(newVal))
-					current, kind = t.extractType(orig)
+					current, kind, wbs = t.extractTypeWriteBack(orig, nil)
 				} else {
-					if err = ct.fn(ctx, fieldLevel{
-						transformer: t,
-						parent:      orig,
-						current:     current,
-						param:       ct.param,
-					}); err != nil {
+					if err = t.call(ctx, loc, ct, orig, current); err != nil {
+						return
+					}
+					if err = runWriteBacks(wbs); err != nil {
 						return
 					}
 					// value could have been changed or reassigned
-					current, kind = t.extractType(current)
+					current, kind, wbs = t.extractTypeWriteBack(current, wbs)
 				}
 				ct = ct.next
 			}
@@ -299,58 +527,238 @@ func (t *Transformer) setByField(ctx context.Context, orig reflect.Value, ct *cT
 			newVal := reflect.New(typ).Elem()
 			newVal.Set(current)
 
-			if err = t.setByStruct(ctx, orig, newVal, typ); err != nil {
+			if err = t.setByStruct(ctx, loc, orig, newVal, typ); err != nil {
 				return
 			}
 			orig.Set(reflect.Indirect(newVal))
 			return
 		}
-		err = t.setByStruct(ctx, orig2, current, typ)
+		err = t.setByStruct(ctx, loc, orig2, current, typ)
 	}
 	return
 }
 
-func (t *Transformer) setByIterable(ctx context.Context, current reflect.Value, ct *cTag) (err error) {
+// call calls the Func of the tag, invoking any registered hooks around it.
+func (t *Transformer) call(ctx context.Context, loc location, ct *cTag, parent, current reflect.Value) error {
+	if ct.accepts != nil && current.IsValid() && !ct.accepts.accepts(current.Type()) && ct.accepts.acceptsElements(current.Type()) {
+		return t.callElements(ctx, loc, ct, current)
+	}
+
+	if ok, err := t.accepts(loc, ct, current); !ok {
+		return err
+	}
+
+	if len(t.hooks) > 0 {
+		return t.observeFunc(ctx, loc, ct, parent, current)
+	}
+	return t.callFunc(ctx, loc, ct, parent, current)
+}
+
+// callFunc calls the Func of the tag using a pooled fieldLevel.
+func (t *Transformer) callFunc(ctx context.Context, loc location, ct *cTag, parent, current reflect.Value) (err error) {
+	if t.recoverPanics {
+		defer recoverPanic(&err, loc, ct.tag)
+	}
+
+	fl := fieldLevelPool.Get().(*fieldLevel)
+	*fl = fieldLevel{
+		transformer: t,
+		parent:      parent,
+		current:     current,
+		param:       ct.param,
+		loc:         loc,
+	}
+
+	err = ct.fn(ctx, fl)
+
+	*fl = fieldLevel{}
+	fieldLevelPool.Put(fl)
+	return
+}
+
+func (t *Transformer) setByIterable(ctx context.Context, loc location, current reflect.Value, ct *cTag) (err error) {
+	parallel := t.parallel(loc, current.Len())
+	if parallel {
+		loc.parallel = true
+	}
+
+	// the iterable location is referenced by each of its elements
+	iterLoc := loc.push()
+	defer iterLoc.release()
+
+	if parallel {
+		return t.runParallel(current.Len(), func(i int) error {
+			return t.setByElem(ctx, iterLoc, current, i, ct)
+		})
+	}
+
+	// called directly, rather than through a closure, as the closure escapes to runParallel
 	for i := 0; i < current.Len(); i++ {
-		if err = t.setByField(ctx, current.Index(i), ct); err != nil {
+		if err = t.setByElem(ctx, iterLoc, current, i, ct); err != nil {
 			return
 		}
 	}
 	return
 }
 
-func (t *Transformer) setByMap(ctx context.Context, current reflect.Value, ct *cTag) error {
-	for _, key := range current.MapKeys() {
+// setByElem transforms the element at index i of the slice or array within the location iterLoc.
+func (t *Transformer) setByElem(ctx context.Context, iterLoc *location, current reflect.Value, i int, ct *cTag) error {
+	elemLoc := iterLoc.index(i)
+	if err := contextDone(ctx, elemLoc); err != nil {
+		return err
+	}
+	if ct != nil && ct.typeof == typeKeys {
+		return t.setByPair(ctx, elemLoc, current.Index(i), ct)
+	}
+	return t.setByField(ctx, elemLoc, current.Index(i), ct)
+}
+
+// mapEntry holds the transformed key and value of a map entry prior to them being written back to the map.
+type mapEntry struct {
+	key    reflect.Value
+	newKey reflect.Value
+	value  reflect.Value
+}
+
+func (t *Transformer) setByMap(ctx context.Context, loc location, current reflect.Value, ct *cTag) error {
+	keys := current.MapKeys()
+	entries := make([]mapEntry, len(keys))
+	transformKeys := ct != nil && ct.typeof == typeKeys && ct.keys != nil
+
+	parallel := t.parallel(loc, len(keys))
+	if parallel || transformKeys {
+		sortMapKeys(keys)
+	}
+	if parallel {
+		loc.parallel = true
+	}
+
+	// the map location is referenced by each of its entries
+	mapLoc := loc.push()
+	defer mapLoc.release()
+
+	// transform copies of each entry, which are written back once all have been transformed, so
+	// that the map is not modified while being read.
+	transform := func(i int) error {
+		key := keys[i]
+		keyLoc := mapLoc.mapKey(key)
+		if err := contextDone(ctx, keyLoc); err != nil {
+			return err
+		}
+
 		newVal := reflect.New(current.Type().Elem()).Elem()
 		newVal.Set(current.MapIndex(key))
 
-		if ct != nil && ct.typeof == typeKeys && ct.keys != nil {
-			// remove current map key as we may be changing it
-			// and re-add to the map afterwards
-			current.SetMapIndex(key, reflect.Value{})
+		entries[i] = mapEntry{key: key, newKey: key, value: newVal}
 
+		if transformKeys {
 			newKey := reflect.New(current.Type().Key()).Elem()
 			newKey.Set(key)
-			key = newKey
+			entries[i].newKey = newKey
 
 			// handle map key
-			if err := t.setByField(ctx, key, ct.keys); err != nil {
+			if err := t.setByField(ctx, keyLoc, newKey, ct.keys); err != nil {
 				return err
 			}
 
 			// can be nil when just keys being validated
 			if ct.next != nil {
-				if err := t.setByField(ctx, newVal, ct.next); err != nil {
+				if err := t.setByField(ctx, keyLoc, newVal, ct.next); err != nil {
 					return err
 				}
 			}
-		} else {
-			if err := t.setByField(ctx, newVal, ct); err != nil {
+			return nil
+		}
+		return t.setByField(ctx, keyLoc, newVal, ct)
+	}
+
+	if parallel {
+		if err := t.runParallel(len(keys), transform); err != nil {
+			return err
+		}
+	} else {
+		for i := range keys {
+			if err := transform(i); err != nil {
 				return err
 			}
 		}
-		current.SetMapIndex(key, newVal)
 	}
 
+	if transformKeys {
+		resolved, err := t.resolveKeyCollisions(ctx, loc, entries)
+		if err != nil {
+			return err
+		}
+
+		// remove current map keys as we may be changing them and re-add to the map afterwards
+		for _, e := range entries {
+			current.SetMapIndex(e.key, reflect.Value{})
+		}
+		entries = resolved
+	}
+
+	for _, e := range entries {
+		current.SetMapIndex(e.newKey, e.value)
+	}
 	return nil
 }
+
+// setByPair applies the keys tags to the Key field, and the remaining tags to the Value field, of a key/value pair
+// struct within a slice or array.
+func (t *Transformer) setByPair(ctx context.Context, loc location, orig reflect.Value, ct *cTag) (err error) {
+	current, kind := t.extractType(orig)
+
+	switch kind {
+	case reflect.Ptr, reflect.Invalid:
+		// nil pointer or interface, nothing to do.
+		return nil
+	case reflect.Struct:
+	default:
+		return &ErrInvalidKeyValuePair{typ: current.Type()}
+	}
+
+	typ := current.Type()
+
+	keyFld, ok := typ.FieldByName(pairKeyField)
+	valueFld, ok2 := typ.FieldByName(pairValueField)
+	if !ok || !ok2 || len(keyFld.Index) > 1 || len(valueFld.Index) > 1 || len(keyFld.PkgPath) > 0 || len(valueFld.PkgPath) > 0 {
+		return &ErrInvalidKeyValuePair{typ: typ}
+	}
+
+	if !current.CanAddr() {
+		newVal := reflect.New(typ).Elem()
+		newVal.Set(current)
+		if err = t.setByPair(ctx, loc, newVal, ct); err != nil {
+			return
+		}
+		orig.Set(newVal)
+		return
+	}
+
+	if err = t.setByField(ctx, loc.field(current, &cField{idx: keyFld.Index[0], name: keyFld.Name, fld: keyFld}), current.Field(keyFld.Index[0]), ct.keys); err != nil {
+		return
+	}
+
+	// can be nil when just keys being transformed
+	if ct.next != nil {
+		err = t.setByField(ctx, loc.field(current, &cField{idx: valueFld.Index[0], name: valueFld.Name, fld: valueFld}), current.Field(valueFld.Index[0]), ct.next)
+	}
+	return
+}
+
+// recoverPanic recovers a panic, setting err to an *ErrPanic describing it. It must be deferred directly.
+func recoverPanic(err *error, loc location, tag string) {
+	if r := recover(); r != nil {
+		*err = &ErrPanic{Namespace: loc.ns(), Tag: tag, Value: r, Stack: debug.Stack()}
+	}
+}
+
+// contextDone returns an *ErrContextDone when the context has been cancelled or its deadline exceeded.
+func contextDone(ctx context.Context, loc location) error {
+	select {
+	case <-ctx.Done():
+		return &ErrContextDone{Namespace: loc.ns(), Err: ctx.Err()}
+	default:
+		return nil
+	}
+}
@@ -4,7 +4,9 @@ import (
 	"context"
 	"errors"
 	"reflect"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -678,3 +680,1320 @@ func TestStructArray(t *testing.T) {
 	Equal(t, len(tt5.ArrNoTag), 1)
 	Equal(t, tt5.ArrNoTag[0].String, "")
 }
+
+func TestFieldLevelLocation(t *testing.T) {
+	type Inner struct {
+		String string `s:"loc"`
+	}
+
+	type Test struct {
+		Inner Inner
+		Arr   []Inner          `s:"dive"`
+		Map   map[string]Inner `s:"dive"`
+		Str   string           `s:"loc" json:"str"`
+	}
+
+	type result struct {
+		name      string
+		ns        string
+		structTyp reflect.Type
+		tag       string
+	}
+
+	var results []result
+	var top interface{}
+
+	set := New()
+	set.SetTagName("s")
+	set.Register("loc", func(ctx context.Context, fl FieldLevel) error {
+		top = fl.Top().Interface()
+		results = append(results, result{
+			name:      fl.FieldName(),
+			ns:        fl.Namespace(),
+			structTyp: fl.Struct().Type(),
+			tag:       fl.StructField().Tag.Get("json"),
+		})
+		return nil
+	})
+
+	tt := Test{
+		Arr: []Inner{{}},
+		Map: map[string]Inner{"key": {}},
+	}
+
+	err := set.Struct(context.Background(), &tt)
+	Equal(t, err, nil)
+	Equal(t, top.(*Test), &tt)
+	Equal(t, len(results), 4)
+	Equal(t, results[0], result{name: "String", ns: "Test.Inner.String", structTyp: reflect.TypeOf(Inner{})})
+	Equal(t, results[1], result{name: "String", ns: "Test.Arr[0].String", structTyp: reflect.TypeOf(Inner{})})
+	Equal(t, results[2], result{name: "String", ns: "Test.Map[key].String", structTyp: reflect.TypeOf(Inner{})})
+	Equal(t, results[3], result{name: "Str", ns: "Test.Str", structTyp: reflect.TypeOf(Test{}), tag: "str"})
+
+	var s string
+	set.Register("field", func(ctx context.Context, fl FieldLevel) error {
+		Equal(t, fl.Top().Kind(), reflect.Ptr)
+		Equal(t, fl.Top().Interface().(*string), &s)
+		Equal(t, fl.Struct().IsValid(), false)
+		Equal(t, fl.FieldName(), "")
+		Equal(t, fl.Namespace(), "")
+		Equal(t, fl.StructField().Name, "")
+		return nil
+	})
+	err = set.Field(context.Background(), &s, "field")
+	Equal(t, err, nil)
+
+	var namespaces []string
+	set = New()
+	set.SetTagName("s")
+	set.Register("loc", func(ctx context.Context, fl FieldLevel) error { return nil })
+	set.RegisterStructLevel(func(ctx context.Context, sl StructLevel) error {
+		Equal(t, sl.Top().Interface(), &tt)
+		namespaces = append(namespaces, sl.Namespace()+"|"+sl.FieldName()+"|"+sl.StructField().Name)
+		return nil
+	}, Inner{})
+
+	err = set.Struct(context.Background(), &tt)
+	Equal(t, err, nil)
+	Equal(t, namespaces, []string{"Test.Inner|Inner|Inner", "Test.Arr[0]|Arr[0]|Arr", "Test.Map[key]|Map[key]|Map"})
+
+	// nested indexes and keys are appended to the field name
+	type Deep struct {
+		Grid  [][]string       `s:"dive,dive,name"`
+		Keyed map[int][]string `s:"dive,dive,name"`
+	}
+
+	namespaces = nil
+	set.Register("name", func(ctx context.Context, fl FieldLevel) error {
+		namespaces = append(namespaces, fl.Namespace()+"|"+fl.FieldName())
+		return nil
+	})
+
+	err = set.Struct(context.Background(), &Deep{Grid: [][]string{{"a", "b"}}, Keyed: map[int][]string{1: {"c"}}})
+	Equal(t, err, nil)
+	Equal(t, namespaces, []string{"Deep.Grid[0][0]|Grid[0][0]", "Deep.Grid[0][1]|Grid[0][1]", "Deep.Keyed[1][0]|Keyed[1][0]"})
+}
+
+func TestRegisterStructTags(t *testing.T) {
+	type Inner struct {
+		String string
+	}
+
+	type Test struct {
+		String  string
+		Tagged  string `s:"repl"`
+		Ignored string `s:"repl"`
+		Inner   Inner
+		Arr     []Inner
+		private string
+	}
+
+	set := New()
+	set.SetTagName("s")
+	set.Register("repl", func(ctx context.Context, fl FieldLevel) error {
+		fl.Field().SetString("test")
+		return nil
+	})
+	set.Register("repl2", func(ctx context.Context, fl FieldLevel) error {
+		fl.Field().SetString("test2")
+		return nil
+	})
+	set.RegisterStructTags(Test{}, map[string]string{
+		"String":  "repl",
+		"Tagged":  "repl2",
+		"Ignored": "-",
+		"Arr":     "dive",
+	})
+	set.RegisterStructTags(&Inner{}, map[string]string{
+		"String": "repl",
+	})
+
+	tt := Test{Arr: make([]Inner, 1)}
+	err := set.Struct(context.Background(), &tt)
+	Equal(t, err, nil)
+	Equal(t, tt.String, "test")
+	Equal(t, tt.Tagged, "test2")
+	Equal(t, tt.Ignored, "")
+	Equal(t, tt.Inner.String, "test")
+	Equal(t, tt.Arr[0].String, "test")
+
+	set2 := New()
+	set2.RegisterStructTags(Inner{}, map[string]string{"String": "undefined"})
+	var inner Inner
+	err = set2.Struct(context.Background(), &inner)
+	NotEqual(t, err, nil)
+	Equal(t, err.Error(), "unregistered/undefined transformation 'undefined' found on field String")
+
+	PanicMatches(t, func() { set.RegisterStructTags("", nil) }, "RegisterStructTags requires a struct type, string provided")
+	PanicMatches(t, func() { set.RegisterStructTags(nil, nil) }, "RegisterStructTags requires a struct type, <nil> provided")
+	PanicMatches(t, func() { set.RegisterStructTags(Test{}, map[string]string{"Missing": "repl"}) }, "Field 'Missing' does not exist or is not exported on type mold.Test")
+	PanicMatches(t, func() { set.RegisterStructTags(Test{}, map[string]string{"private": "repl"}) }, "Field 'private' does not exist or is not exported on type mold.Test")
+}
+
+func TestConfig(t *testing.T) {
+	type Address struct {
+		Street string
+	}
+
+	type User struct {
+		Name      string `s:"repl"`
+		Email     string
+		Addresses []*Address
+	}
+
+	set := New()
+	set.SetTagName("s")
+	set.Register("repl", func(ctx context.Context, fl FieldLevel) error {
+		fl.Field().SetString("test")
+		return nil
+	})
+	set.Register("trim", func(ctx context.Context, fl FieldLevel) error {
+		fl.Field().SetString(strings.TrimSpace(fl.Field().String()))
+		return nil
+	})
+	set.Register("lcase", func(ctx context.Context, fl FieldLevel) error {
+		fl.Field().SetString(strings.ToLower(fl.Field().String()))
+		return nil
+	})
+
+	cfg, err := DecodeConfig(strings.NewReader(`{
+		"aliases": {"normalize": "trim,lcase"},
+		"types": {
+			"mold.User": {"Name": "-", "Email": "normalize", "Addresses": "dive", "Addresses.Street": "trim"}
+		}
+	}`))
+	Equal(t, err, nil)
+
+	err = set.ApplyConfig(cfg, User{})
+	Equal(t, err, nil)
+
+	u := User{Name: "name", Email: " Joe@Example.com ", Addresses: []*Address{{Street: " street "}}}
+	err = set.Struct(context.Background(), &u)
+	Equal(t, err, nil)
+	Equal(t, u.Name, "name")
+	Equal(t, u.Email, "joe@example.com")
+	Equal(t, u.Addresses[0].Street, "street")
+
+	s := " A "
+	err = set.Field(context.Background(), &s, "normalize")
+	Equal(t, err, nil)
+	Equal(t, s, "a")
+
+	// reload replaces the previous rules
+	err = set.ApplyConfig(Config{Types: map[string]map[string]string{"github.com/go-playground/mold/v4.User": {"Email": "trim"}}}, &User{})
+	Equal(t, err, nil)
+
+	u = User{Email: " Joe@Example.com "}
+	err = set.Struct(context.Background(), &u)
+	Equal(t, err, nil)
+	Equal(t, u.Name, "test")
+	Equal(t, u.Email, "Joe@Example.com")
+
+	s = " A "
+	err = set.Field(context.Background(), &s, "normalize")
+	NotEqual(t, err, nil)
+
+	tests := []struct {
+		cfg      Config
+		expected string
+	}{
+		{
+			cfg:      Config{Aliases: map[string]string{"a,b": "trim"}},
+			expected: "mold: invalid config at aliases[a,b]: Alias 'a,b' either contains restricted characters or is the same as a restricted tag needed for normal operation",
+		},
+		{
+			cfg:      Config{Aliases: map[string]string{"a": ""}},
+			expected: "mold: invalid config at aliases[a]: aliased tags cannot be empty",
+		},
+		{
+			cfg:      Config{Aliases: map[string]string{"a": "trim,b", "b": "a"}},
+			expected: "mold: invalid config at aliases[a]: alias cycle detected a -> b -> a",
+		},
+		{
+			cfg:      Config{Aliases: map[string]string{"a": "trim,undefined"}},
+			expected: "mold: invalid config at aliases[a]: unregistered/undefined transformation 'undefined' found on field",
+		},
+		{
+			cfg:      Config{Types: map[string]map[string]string{"mold.Unknown": {"Name": "trim"}}},
+			expected: "mold: invalid config at types[mold.Unknown]: unknown type",
+		},
+		{
+			cfg:      Config{Types: map[string]map[string]string{"mold.User": {"Addresses.Missing": "trim"}}},
+			expected: "mold: invalid config at types[mold.User].Addresses.Missing: field 'Missing' does not exist or is not exported on type mold.Address",
+		},
+		{
+			cfg:      Config{Types: map[string]map[string]string{"mold.User": {"Name.Inner": "trim"}}},
+			expected: "mold: invalid config at types[mold.User].Name.Inner: field Name of type string is not a struct",
+		},
+		{
+			cfg:      Config{Types: map[string]map[string]string{"mold.User": {"Addresses.Street": "trim"}}},
+			expected: "mold: invalid config at types[mold.User].Addresses.Street: field Addresses of type mold.User must be tagged 'dive' to reach its elements",
+		},
+		{
+			cfg:      Config{Types: map[string]map[string]string{"mold.User": {"Email": "trim,undefined"}}},
+			expected: "mold: invalid config at types[mold.User].Email: unregistered/undefined transformation 'undefined' found on field Email",
+		},
+	}
+
+	// cycles crossing registered aliases
+	set.RegisterAlias("registered", "trim,cyclic")
+	tests = append(tests, struct {
+		cfg      Config
+		expected string
+	}{
+		cfg:      Config{Aliases: map[string]string{"cyclic": "trim,registered"}},
+		expected: "mold: invalid config at aliases[cyclic]: alias cycle detected cyclic -> registered -> cyclic",
+	})
+
+	for _, tc := range tests {
+		err = set.ApplyConfig(tc.cfg, User{})
+		NotEqual(t, err, nil)
+		Equal(t, err.Error(), tc.expected)
+
+		var ce *ErrConfig
+		Equal(t, errors.As(err, &ce), true)
+	}
+
+	// failed applies leave the previous rules in place
+	u = User{Email: " Joe@Example.com "}
+	err = set.Struct(context.Background(), &u)
+	Equal(t, err, nil)
+	Equal(t, u.Email, "Joe@Example.com")
+
+	err = set.ApplyConfig(Config{}, 1)
+	NotEqual(t, err, nil)
+	Equal(t, err.Error(), "mold: ApplyConfig requires struct types, int provided")
+
+	_, err = DecodeConfig(strings.NewReader(`{"aliases": {"a": }`))
+	NotEqual(t, err, nil)
+	Equal(t, err.Error(), "mold: invalid config at offset 19: invalid character '}' looking for beginning of value")
+
+	_, err = DecodeConfig(strings.NewReader(`{"unknown": {}}`))
+	NotEqual(t, err, nil)
+
+	_, err = DecodeConfig(strings.NewReader(`{"aliases": 1}`))
+	NotEqual(t, err, nil)
+	Equal(t, err.Error(), "mold: invalid config at offset 13: json: cannot unmarshal number into Go struct field Config.aliases of type map[string]string")
+
+	cfg, err = DecodeConfigYAML(strings.NewReader(`
+aliases:
+  normalize: trim,lcase
+types:
+  mold.User:
+    Email: normalize
+`))
+	Equal(t, err, nil)
+	Equal(t, cfg, Config{Aliases: map[string]string{"normalize": "trim,lcase"}, Types: map[string]map[string]string{"mold.User": {"Email": "normalize"}}})
+
+	err = set.ApplyConfig(cfg, User{})
+	Equal(t, err, nil)
+
+	u = User{Email: " Joe@Example.com "}
+	err = set.Struct(context.Background(), &u)
+	Equal(t, err, nil)
+	Equal(t, u.Email, "joe@example.com")
+
+	_, err = DecodeConfigYAML(strings.NewReader("aliases:\n  a: [trim\n"))
+	NotEqual(t, err, nil)
+	Equal(t, err.Error(), "mold: invalid config at line 1: yaml: line 1: did not find expected ',' or ']'")
+
+	_, err = DecodeConfigYAML(strings.NewReader("aliases:\n  a: trim\nunknown: 1\n"))
+	NotEqual(t, err, nil)
+	Equal(t, err.Error(), "mold: invalid config at line 3: field unknown not found in type mold.Config")
+
+	var ce *ErrConfig
+	Equal(t, errors.As(err, &ce), true)
+	Equal(t, ce.Location, "line 3")
+}
+
+func TestConfigReload(t *testing.T) {
+	type User struct {
+		Name string
+	}
+
+	set := New()
+	set.Register("trim", func(ctx context.Context, fl FieldLevel) error {
+		fl.Field().SetString(strings.TrimSpace(fl.Field().String()))
+		return nil
+	})
+	set.Register("lcase", func(ctx context.Context, fl FieldLevel) error {
+		fl.Field().SetString(strings.ToLower(fl.Field().String()))
+		return nil
+	})
+
+	configs := []Config{
+		{Aliases: map[string]string{"normalize": "trim"}, Types: map[string]map[string]string{"mold.User": {"Name": "normalize"}}},
+		{Aliases: map[string]string{"normalize": "trim,lcase"}, Types: map[string]map[string]string{"mold.User": {"Name": "normalize"}}},
+	}
+	Equal(t, set.ApplyConfig(configs[0], User{}), nil)
+
+	// reloading while transforming
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				u := User{Name: " Name "}
+				if err := set.Struct(context.Background(), &u); err != nil || (u.Name != "Name" && u.Name != "name") {
+					t.Errorf("unexpected result %q %v", u.Name, err)
+					return
+				}
+				s := " Name "
+				if err := set.Field(context.Background(), &s, "normalize"); err != nil {
+					t.Errorf("unexpected error %v", err)
+					return
+				}
+			}
+		}()
+	}
+	for i := 0; i < 50; i++ {
+		Equal(t, set.ApplyConfig(configs[i%2], User{}), nil)
+	}
+	wg.Wait()
+
+	Equal(t, set.ApplyConfig(configs[1], User{}), nil)
+	u := User{Name: " Name "}
+	Equal(t, set.Struct(context.Background(), &u), nil)
+	Equal(t, u.Name, "name")
+}
+
+func TestIncludeUnexported(t *testing.T) {
+	type inner struct {
+		value string `s:"repl"`
+	}
+
+	type Test struct {
+		value   string `s:"repl"`
+		ptr     *string
+		inner   inner
+		inners  []inner `s:"dive"`
+		ignored string  `s:"-"`
+		Value   string  `s:"repl"`
+	}
+
+	set := New()
+	set.SetTagName("s")
+	set.Register("repl", func(ctx context.Context, fl FieldLevel) error {
+		fl.Field().SetString("test")
+		return nil
+	})
+
+	tt := Test{inners: make([]inner, 1)}
+	err := set.Struct(context.Background(), &tt)
+	Equal(t, err, nil)
+	Equal(t, tt.value, "")
+	Equal(t, tt.Value, "test")
+
+	set.SetIncludeUnexported(true)
+
+	tt = Test{inners: make([]inner, 1)}
+	err = set.Struct(context.Background(), &tt)
+	Equal(t, err, nil)
+	Equal(t, tt.value, "test")
+	Equal(t, tt.ptr, nil)
+	Equal(t, tt.inner.value, "test")
+	Equal(t, tt.inners[0].value, "test")
+	Equal(t, tt.ignored, "")
+	Equal(t, tt.Value, "test")
+
+	type Wrapper struct {
+		Iface interface{}
+	}
+
+	w := Wrapper{Iface: inner{}}
+	err = set.Struct(context.Background(), &w)
+	Equal(t, err, nil)
+	Equal(t, w.Iface.(inner).value, "test")
+}
+
+func TestEmbeddedPromote(t *testing.T) {
+	type Core struct {
+		ID string
+	}
+
+	type Base struct {
+		Core  `s:"promote,ucase"`
+		Name  string `s:"prefix=name-"`
+		Ptr   *string
+		Count int
+		Skip  string `s:"-"`
+	}
+
+	type Outer struct {
+		Base  `s:"promote,trim"`
+		Title string
+	}
+
+	type Override struct {
+		*Base `s:"promote,trim"`
+	}
+
+	set := New()
+	set.SetTagName("s")
+	set.Register("trim", func(ctx context.Context, fl FieldLevel) error {
+		if fl.Field().Kind() == reflect.String {
+			fl.Field().SetString(strings.TrimSpace(fl.Field().String()))
+		}
+		return nil
+	})
+	set.Register("ucase", func(ctx context.Context, fl FieldLevel) error {
+		if fl.Field().Kind() == reflect.String {
+			fl.Field().SetString(strings.ToUpper(fl.Field().String()))
+		}
+		return nil
+	})
+	set.Register("prefix", func(ctx context.Context, fl FieldLevel) error {
+		fl.Field().SetString(fl.Param() + fl.Field().String())
+		return nil
+	})
+	set.RegisterStructTags(Override{}, map[string]string{"Name": "ucase", "ID": "-"})
+
+	s := " ptr "
+	o := Outer{Base: Base{Core: Core{ID: " id "}, Name: " n ", Ptr: &s, Skip: " skip "}, Title: " title "}
+	err := set.Struct(context.Background(), &o)
+	Equal(t, err, nil)
+	Equal(t, o.ID, "ID")
+	Equal(t, o.Name, "name- n")
+	Equal(t, *o.Ptr, "ptr")
+	Equal(t, o.Skip, " skip ")
+	Equal(t, o.Title, " title ")
+
+	// promoted tags are only applied when part of the outer struct
+	b := Base{Core: Core{ID: " id "}, Name: " n "}
+	err = set.Struct(context.Background(), &b)
+	Equal(t, err, nil)
+	Equal(t, b.ID, " ID ")
+	Equal(t, b.Name, "name- n ")
+
+	ov := Override{Base: &Base{Core: Core{ID: " id "}, Name: " n "}}
+	err = set.Struct(context.Background(), &ov)
+	Equal(t, err, nil)
+	Equal(t, ov.ID, " id ")
+	Equal(t, ov.Name, "N")
+
+	type Invalid struct {
+		Name string `s:"promote,trim"`
+	}
+
+	var inv Invalid
+	err = set.Struct(context.Background(), &inv)
+	Equal(t, err, ErrInvalidPromoteTag)
+
+	type Shadow struct {
+		Base `s:"promote,ucase"`
+		Name string
+	}
+
+	set2 := New()
+	set2.SetTagName("s")
+	set2.Register("trim", func(ctx context.Context, fl FieldLevel) error { return nil })
+	set2.Register("ucase", func(ctx context.Context, fl FieldLevel) error {
+		if fl.Field().Kind() == reflect.String {
+			fl.Field().SetString(strings.ToUpper(fl.Field().String()))
+		}
+		return nil
+	})
+	set2.Register("prefix", func(ctx context.Context, fl FieldLevel) error { return nil })
+	set2.RegisterStructTags(Shadow{}, map[string]string{"Name": "ucase"})
+
+	sh := Shadow{Base: Base{Name: "inner"}, Name: "outer"}
+	err = set2.Struct(context.Background(), &sh)
+	Equal(t, err, nil)
+	Equal(t, sh.Name, "OUTER")
+	Equal(t, sh.Base.Name, "INNER")
+}
+
+type promoteCycleA struct {
+	*promoteCycleB `s:"promote,trim"`
+	Name           string
+}
+
+type promoteCycleB struct {
+	*promoteCycleA
+	S string
+}
+
+func TestEmbeddedPromoteCycle(t *testing.T) {
+	set := New()
+	set.SetTagName("s")
+	set.Register("trim", func(ctx context.Context, fl FieldLevel) error {
+		fl.Field().SetString(strings.TrimSpace(fl.Field().String()))
+		return nil
+	})
+
+	a := promoteCycleA{promoteCycleB: &promoteCycleB{promoteCycleA: &promoteCycleA{Name: " inner "}, S: " s "}, Name: " a "}
+	err := set.Struct(context.Background(), &a)
+	Equal(t, err, nil)
+	Equal(t, a.Name, " a ")
+	Equal(t, a.S, "s")
+	Equal(t, a.promoteCycleB.promoteCycleA.Name, " inner ")
+}
+
+func TestContextCancellation(t *testing.T) {
+	type Inner struct {
+		String string `s:"cancel"`
+	}
+
+	type Test struct {
+		Arr []Inner          `s:"dive"`
+		Map map[string]Inner `s:"dive"`
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var calls int
+
+	set := New()
+	set.SetTagName("s")
+	set.Register("cancel", func(ctx context.Context, fl FieldLevel) error {
+		calls++
+		if calls == 2 {
+			cancel()
+		}
+		return nil
+	})
+
+	tt := Test{Arr: make([]Inner, 5)}
+	err := set.Struct(ctx, &tt)
+	NotEqual(t, err, nil)
+	Equal(t, errors.Is(err, context.Canceled), true)
+	Equal(t, err.Error(), "mold: transformation stopped at 'Test.Arr[2]': context canceled")
+	Equal(t, calls, 2)
+
+	var ce *ErrContextDone
+	Equal(t, errors.As(err, &ce), true)
+	Equal(t, ce.Namespace, "Test.Arr[2]")
+
+	tt = Test{Map: map[string]Inner{"a": {}}}
+	err = set.Struct(ctx, &tt)
+	NotEqual(t, err, nil)
+	Equal(t, err.Error(), "mold: transformation stopped at 'Test.Arr': context canceled")
+
+	m := map[string]string{"key": ""}
+	err = set.Field(ctx, &m, "dive,cancel")
+	NotEqual(t, err, nil)
+	Equal(t, err.Error(), "mold: transformation stopped at '[key]': context canceled")
+
+	dctx, dcancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer dcancel()
+
+	s, err := set.CompileSchema(map[string]string{"a": "cancel"})
+	Equal(t, err, nil)
+
+	var buf strings.Builder
+	err = set.Stream(dctx, strings.NewReader(`{"a": "b"}`), &buf, s)
+	NotEqual(t, err, nil)
+	Equal(t, errors.Is(err, context.DeadlineExceeded), true)
+}
+
+func TestConcurrency(t *testing.T) {
+	type Inner struct {
+		String string   `s:"repl"`
+		Nested []string `s:"dive,repl"`
+	}
+
+	type Test struct {
+		Arr []Inner          `s:"dive"`
+		Map map[string]Inner `s:"dive"`
+		Ptr *[]string        `s:"dive,repl"`
+	}
+
+	set := New()
+	set.SetTagName("s")
+	set.SetConcurrency(4, 10)
+	set.Register("repl", func(ctx context.Context, fl FieldLevel) error {
+		if strings.HasPrefix(fl.Field().String(), "error") {
+			return errors.New(fl.Field().String() + " at " + fl.Namespace())
+		}
+		fl.Field().SetString("test")
+		return nil
+	})
+
+	tt := Test{
+		Arr: make([]Inner, 1000),
+		Map: make(map[string]Inner),
+	}
+	for i := 0; i < 100; i++ {
+		tt.Map[strconv.Itoa(i)] = Inner{Nested: make([]string, 20)}
+	}
+	tt.Arr[0].Nested = make([]string, 20)
+
+	err := set.Struct(context.Background(), &tt)
+	Equal(t, err, nil)
+
+	for _, inner := range tt.Arr {
+		Equal(t, inner.String, "test")
+	}
+	Equal(t, tt.Arr[0].Nested[19], "test")
+	Equal(t, len(tt.Map), 100)
+	for _, inner := range tt.Map {
+		Equal(t, inner.String, "test")
+		Equal(t, inner.Nested[19], "test")
+	}
+
+	// the error of the lowest index is returned
+	for i := 0; i < 10; i++ {
+		tt.Arr[999].String = "error2"
+		tt.Arr[3].String = "error1"
+		tt.Arr[400].String = "error3"
+
+		err = set.Struct(context.Background(), &tt)
+		NotEqual(t, err, nil)
+		Equal(t, err.Error(), "error1 at Test.Arr[3].String")
+	}
+
+	for i := 0; i < 10; i++ {
+		tt.Arr[3].String, tt.Arr[400].String, tt.Arr[999].String = "", "", ""
+		tt.Map["99"] = Inner{String: "error2"}
+		tt.Map["10"] = Inner{String: "error1"}
+
+		err = set.Struct(context.Background(), &tt)
+		NotEqual(t, err, nil)
+		Equal(t, err.Error(), "error1 at Test.Map[10].String")
+	}
+
+	// below the minimum length
+	s := []string{"a", "b"}
+	err = set.Field(context.Background(), &s, "dive,repl")
+	Equal(t, err, nil)
+	Equal(t, s, []string{"test", "test"})
+
+	m := map[string]string{"a": "", "b": "", "c": "", "d": "", "e": "", "f": "", "g": "", "h": "", "i": "", "j": "", "k": ""}
+	err = set.Field(context.Background(), &m, "dive,keys,repl,endkeys")
+	Equal(t, err, nil)
+	Equal(t, m, map[string]string{"test": ""})
+}
+
+func TestKeyCollision(t *testing.T) {
+	type Test struct {
+		Map map[string]int `s:"dive,keys,lcase,endkeys"`
+	}
+
+	set := New()
+	set.SetTagName("s")
+	set.Register("lcase", func(ctx context.Context, fl FieldLevel) error {
+		fl.Field().SetString(strings.ToLower(fl.Field().String()))
+		return nil
+	})
+
+	newTest := func() Test {
+		return Test{Map: map[string]int{"foo": 1, "Foo": 2, "FOO": 3, "bar": 4}}
+	}
+
+	// keys are resolved in sorted order: FOO, Foo, foo
+	tt := newTest()
+	err := set.Struct(context.Background(), &tt)
+	Equal(t, err, nil)
+	Equal(t, tt.Map, map[string]int{"foo": 1, "bar": 4})
+
+	set.SetKeyCollisionPolicy(KeyCollisionKeepFirst, nil)
+	tt = newTest()
+	err = set.Struct(context.Background(), &tt)
+	Equal(t, err, nil)
+	Equal(t, tt.Map, map[string]int{"foo": 3, "bar": 4})
+
+	set.SetKeyCollisionPolicy(KeyCollisionError, nil)
+	tt = newTest()
+	err = set.Struct(context.Background(), &tt)
+	NotEqual(t, err, nil)
+	Equal(t, err.Error(), "mold: map keys 'FOO' and 'Foo' collide as 'foo' at 'Test.Map'")
+	Equal(t, tt.Map, newTest().Map)
+
+	var kce *ErrKeyCollision
+	Equal(t, errors.As(err, &kce), true)
+	Equal(t, kce.Key, "foo")
+
+	set.SetKeyCollisionPolicy(KeyCollisionMerge, func(ctx context.Context, key, existing, value reflect.Value) (reflect.Value, error) {
+		if existing.Int()+value.Int() > 100 {
+			return reflect.Value{}, errors.New("too large")
+		}
+		return reflect.ValueOf(int(existing.Int() + value.Int())), nil
+	})
+	tt = newTest()
+	err = set.Struct(context.Background(), &tt)
+	Equal(t, err, nil)
+	Equal(t, tt.Map, map[string]int{"foo": 6, "bar": 4})
+
+	tt = newTest()
+	tt.Map["foo"] = 100
+	err = set.Struct(context.Background(), &tt)
+	NotEqual(t, err, nil)
+	Equal(t, err.Error(), "too large")
+
+	PanicMatches(t, func() { set.SetKeyCollisionPolicy(KeyCollisionMerge, nil) }, "KeyMergeFunc cannot be empty when using KeyCollisionMerge")
+	PanicMatches(t, func() { set.SetKeyCollisionPolicy(KeyCollisionPolicy(10), nil) }, "invalid key collision policy 10")
+}
+
+func TestStructKeysAndPairs(t *testing.T) {
+	type Key struct {
+		Name string `s:"trim"`
+	}
+
+	type Pair struct {
+		Key   string
+		Value string
+	}
+
+	type Test struct {
+		Map      map[Key]string    `s:"dive,keys,endkeys"`
+		KeysOnly map[Key]Key       `s:"dive,keys,endkeys"`
+		Both     map[Key]string    `s:"dive,keys,endkeys,trim"`
+		Ptrs     map[*Key]string   `s:"dive,keys,trim"`
+		Pairs    []Pair            `s:"dive,keys,trim,endkeys,trim"`
+		PairPtrs []*Pair           `s:"dive,keys,trim"`
+		Iface    []interface{}     `s:"dive,keys,trim,endkeys,trim"`
+		Invalid  []string          `s:"dive,keys,trim"`
+		Err      map[string]string `s:"dive,keys,endkeys"`
+		Nested   map[string][]Pair `s:"dive,dive,keys,trim"`
+	}
+
+	set := New()
+	set.SetTagName("s")
+	set.Register("trim", func(ctx context.Context, fl FieldLevel) error {
+		if fl.Field().Kind() == reflect.String {
+			fl.Field().SetString(strings.TrimSpace(fl.Field().String()))
+		}
+		return nil
+	})
+
+	tt := Test{
+		Map:      map[Key]string{{Name: " a "}: " b "},
+		KeysOnly: map[Key]Key{{Name: " a "}: {Name: " b "}},
+		Both:     map[Key]string{{Name: " a "}: " b "},
+		Ptrs:     map[*Key]string{{Name: " a "}: " b "},
+		Pairs:    []Pair{{Key: " a ", Value: " b "}},
+		PairPtrs: []*Pair{{Key: " a ", Value: " b "}, nil},
+		Iface:    []interface{}{Pair{Key: " a ", Value: " b "}, &Pair{Key: " c ", Value: " d "}},
+		Nested:   map[string][]Pair{"x": {{Key: " a ", Value: " b "}}},
+	}
+
+	err := set.Struct(context.Background(), &tt)
+	Equal(t, err, nil)
+	Equal(t, tt.Map, map[Key]string{{Name: "a"}: " b "})
+	Equal(t, tt.KeysOnly, map[Key]Key{{Name: "a"}: {Name: " b "}})
+	Equal(t, tt.Both, map[Key]string{{Name: "a"}: "b"})
+	for k, v := range tt.Ptrs {
+		Equal(t, k.Name, "a")
+		Equal(t, v, " b ")
+	}
+	Equal(t, tt.Pairs, []Pair{{Key: "a", Value: "b"}})
+	Equal(t, *tt.PairPtrs[0], Pair{Key: "a", Value: " b "})
+	Equal(t, tt.Iface[0], Pair{Key: "a", Value: "b"})
+	Equal(t, *tt.Iface[1].(*Pair), Pair{Key: "c", Value: "d"})
+	Equal(t, tt.Nested["x"], []Pair{{Key: "a", Value: " b "}})
+
+	tt.Invalid = []string{"a"}
+	err = set.Struct(context.Background(), &tt)
+	NotEqual(t, err, nil)
+	Equal(t, err.Error(), "mold: 'keys' tag requires slice or array elements to be structs with exported Key and Value fields, found string")
+
+	// the namespace of fields within keys and pairs
+	set = New()
+	set.SetTagName("s")
+	set.Register("trim", func(ctx context.Context, fl FieldLevel) error {
+		return errors.New(fl.Namespace())
+	})
+
+	err = set.Struct(context.Background(), &Test{Map: map[Key]string{{Name: "a"}: "b"}})
+	NotEqual(t, err, nil)
+	Equal(t, err.Error(), "Test.Map[{a}].Name")
+
+	err = set.Struct(context.Background(), &Test{Pairs: []Pair{{Key: "a", Value: "b"}}})
+	NotEqual(t, err, nil)
+	Equal(t, err.Error(), "Test.Pairs[0].Key")
+}
+
+type benchStrings struct {
+	First  string `mold:"trim"`
+	Last   string `mold:"trim"`
+	Email  string `mold:"trim,lcase"`
+	Street string `mold:"trim"`
+	City   string `mold:"trim"`
+}
+
+func newBenchTransformer() *Transformer {
+	set := New()
+	set.Register("trim", func(ctx context.Context, fl FieldLevel) error {
+		fl.Field().SetString(strings.TrimSpace(fl.Field().String()))
+		return nil
+	})
+	set.Register("lcase", func(ctx context.Context, fl FieldLevel) error {
+		if s := fl.Field().String(); strings.ToLower(s) != s {
+			fl.Field().SetString(strings.ToLower(s))
+		}
+		return nil
+	})
+	return set
+}
+
+func BenchmarkStructStrings(b *testing.B) {
+	set := newBenchTransformer()
+	ctx := context.Background()
+	v := &benchStrings{First: " Joey ", Last: " Bloggs ", Email: " joey@example.com ", Street: " 1 Main St ", City: " Town "}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = set.Struct(ctx, v)
+	}
+}
+
+func BenchmarkStructStringsParallel(b *testing.B) {
+	set := newBenchTransformer()
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		v := &benchStrings{First: " Joey ", Last: " Bloggs ", Email: " joey@example.com ", Street: " 1 Main St ", City: " Town "}
+		for pb.Next() {
+			_ = set.Struct(ctx, v)
+		}
+	})
+}
+
+func BenchmarkStructNested(b *testing.B) {
+	type Test struct {
+		Name    string `mold:"trim"`
+		Address benchStrings
+	}
+
+	set := newBenchTransformer()
+	ctx := context.Background()
+	v := &Test{Name: " Joey ", Address: benchStrings{City: " Town "}}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = set.Struct(ctx, v)
+	}
+}
+
+func BenchmarkFieldString(b *testing.B) {
+	set := newBenchTransformer()
+	ctx := context.Background()
+	s := " Joey "
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = set.Field(ctx, &s, "trim,lcase")
+	}
+}
+
+func BenchmarkFieldDive(b *testing.B) {
+	set := newBenchTransformer()
+	ctx := context.Background()
+	s := []string{" a ", " b ", " c "}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = set.Field(ctx, &s, "dive,trim")
+	}
+}
+
+func TestZeroAllocs(t *testing.T) {
+	if raceEnabled {
+		t.Skip("sync.Pool does not reuse values when the race detector is enabled")
+	}
+
+	type Nested struct {
+		Name      string `mold:"trim"`
+		Address   benchStrings
+		Addresses []benchStrings
+		Names     []string `mold:"dive,trim"`
+	}
+
+	set := newBenchTransformer()
+	ctx := context.Background()
+	v := &benchStrings{First: " Joey ", Last: " Bloggs ", Email: " Joey@example.com "}
+	n := &Nested{Name: " Joey ", Address: benchStrings{City: " Town "}, Addresses: make([]benchStrings, 3), Names: []string{" a ", " b "}}
+	s := " Joey "
+	ss := []string{" a ", " b ", " c "}
+
+	// warm the caches and pools
+	_ = set.Struct(ctx, v)
+	_ = set.Struct(ctx, n)
+	_ = set.Field(ctx, &s, "trim,lcase")
+	_ = set.Field(ctx, &ss, "dive,trim")
+
+	allocs := testing.AllocsPerRun(100, func() {
+		_ = set.Struct(ctx, v)
+	})
+	Equal(t, allocs, float64(0))
+
+	allocs = testing.AllocsPerRun(100, func() {
+		_ = set.Struct(ctx, n)
+	})
+	Equal(t, allocs, float64(0))
+
+	allocs = testing.AllocsPerRun(100, func() {
+		_ = set.Field(ctx, &s, "trim,lcase")
+	})
+	Equal(t, allocs, float64(0))
+
+	allocs = testing.AllocsPerRun(100, func() {
+		_ = set.Field(ctx, &ss, "dive,trim")
+	})
+	Equal(t, allocs, float64(0))
+}
+
+func TestSkipUntagged(t *testing.T) {
+	type Inert struct {
+		Values []string
+		Map    map[string]string
+		Time   time.Time
+		Next   *Inert
+	}
+
+	type Level struct {
+		Value string
+	}
+
+	type Tagged struct {
+		Inert  Inert
+		Name   string `mold:"trim"`
+		Parent *Tagged
+	}
+
+	type Intercepted struct {
+		Value string
+	}
+
+	type Test struct {
+		Inert       Inert
+		InertPtr    *Inert
+		Tagged      Tagged
+		Iface       interface{}
+		Level       Level
+		Intercepted Intercepted
+		Ignored     Tagged `mold:"-"`
+		Time        time.Time
+	}
+
+	set := New()
+	set.Register("trim", func(ctx context.Context, fl FieldLevel) error {
+		fl.Field().SetString(strings.TrimSpace(fl.Field().String()))
+		return nil
+	})
+	set.RegisterStructLevel(func(ctx context.Context, sl StructLevel) error {
+		sl.Struct().Field(0).SetString("level")
+		return nil
+	}, Level{})
+	set.RegisterInterceptor(func(current reflect.Value) reflect.Value {
+		return current.Field(0)
+	}, Intercepted{})
+
+	cs, err := set.extractStructCache(reflect.ValueOf(Test{}))
+	Equal(t, err, nil)
+
+	var names []string
+	for _, f := range cs.fields {
+		names = append(names, f.name)
+	}
+	Equal(t, names, []string{"Tagged", "Iface", "Level", "Intercepted"})
+
+	cs, err = set.extractStructCache(reflect.ValueOf(Tagged{}))
+	Equal(t, err, nil)
+	Equal(t, len(cs.fields), 2)
+
+	tt := Test{
+		Tagged: Tagged{Name: " a ", Parent: &Tagged{Name: " b "}},
+		Iface:  &Tagged{Name: " c "},
+	}
+	err = set.Struct(context.Background(), &tt)
+	Equal(t, err, nil)
+	Equal(t, tt.Tagged.Name, "a")
+	Equal(t, tt.Tagged.Parent.Name, "b")
+	Equal(t, tt.Iface.(*Tagged).Name, "c")
+	Equal(t, tt.Level.Value, "level")
+
+	// registering against a previously skipped type includes it
+	set.RegisterStructTags(Inert{}, map[string]string{"Values": "dive,trim"})
+
+	cs, err = set.extractStructCache(reflect.ValueOf(Test{}))
+	Equal(t, err, nil)
+	Equal(t, len(cs.fields), 6)
+}
+
+func BenchmarkStructUntagged(b *testing.B) {
+	type Inert struct {
+		Values [64]string
+		Nested [16]struct {
+			A, B, C string
+		}
+	}
+
+	type Test struct {
+		Name  string `mold:"trim"`
+		Inert Inert
+	}
+
+	set := newBenchTransformer()
+	ctx := context.Background()
+	v := &Test{Name: " Joey "}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = set.Struct(ctx, v)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	type Test struct {
+		Name  string `mold:"trim"`
+		Count int    `mold:"trim"`
+		Email string `mold:"lcase"`
+	}
+
+	set := New()
+	set.Register("trim", func(ctx context.Context, fl FieldLevel) error {
+		fl.Field().SetString(strings.TrimSpace(fl.Field().String()))
+		return nil
+	})
+
+	var calls []string
+	set.Use(func(name string, next Func) Func {
+		return func(ctx context.Context, fl FieldLevel) error {
+			calls = append(calls, "outer "+name+" "+fl.Namespace())
+			return next(ctx, fl)
+		}
+	}, func(name string, next Func) Func {
+		return func(ctx context.Context, fl FieldLevel) error {
+			// only strings
+			if fl.Field().Kind() != reflect.String {
+				return nil
+			}
+			calls = append(calls, "inner "+name)
+			return next(ctx, fl)
+		}
+	})
+
+	// registered after the middleware was added
+	set.Register("lcase", func(ctx context.Context, fl FieldLevel) error {
+		fl.Field().SetString(strings.ToLower(fl.Field().String()))
+		return nil
+	})
+
+	tt := Test{Name: " a ", Count: 1, Email: "B"}
+	err := set.Struct(context.Background(), &tt)
+	Equal(t, err, nil)
+	Equal(t, tt.Name, "a")
+	Equal(t, tt.Email, "b")
+	Equal(t, calls, []string{
+		"outer trim Test.Name",
+		"inner trim",
+		"outer trim Test.Count",
+		"outer lcase Test.Email",
+		"inner lcase",
+	})
+
+	// applied to previously compiled tags
+	set.Use(func(name string, next Func) Func {
+		return func(ctx context.Context, fl FieldLevel) error {
+			return errors.New("stopped " + name)
+		}
+	})
+	err = set.Struct(context.Background(), &tt)
+	NotEqual(t, err, nil)
+	Equal(t, err.Error(), "stopped trim")
+
+	PanicMatches(t, func() { set.Use(nil) }, "Middleware cannot be empty")
+}
+
+func TestRecoverPanics(t *testing.T) {
+	type Level struct {
+		Value string
+	}
+
+	type Test struct {
+		Name   string   `mold:"trim"`
+		Count  int      `mold:"trim"`
+		Level  Level    `mold:""`
+		Values []int    `mold:"dive,trim"`
+		Many   []string `mold:"dive,trim"`
+	}
+
+	set := New()
+	set.Register("trim", func(ctx context.Context, fl FieldLevel) error {
+		fl.Field().SetString(strings.TrimSpace(fl.Field().String()))
+		return nil
+	})
+
+	PanicMatches(t, func() { _ = set.Struct(context.Background(), &Test{}) }, "reflect: call of reflect.Value.SetString on int Value")
+
+	set.SetRecoverPanics(true)
+
+	err := set.Struct(context.Background(), &Test{})
+	NotEqual(t, err, nil)
+	Equal(t, err.Error(), "mold: panic applying 'trim' at 'Test.Count': reflect: call of reflect.Value.SetString on int Value")
+
+	var pe *ErrPanic
+	Equal(t, errors.As(err, &pe), true)
+	Equal(t, pe.Namespace, "Test.Count")
+	Equal(t, pe.Tag, "trim")
+	Equal(t, strings.Contains(string(pe.Stack), "TestRecoverPanics"), true)
+
+	// within goroutines transforming concurrently
+	set.SetConcurrency(4, 10)
+
+	tt := Test{Values: make([]int, 100)}
+	set.RegisterStructTags(Test{}, map[string]string{"Count": ""})
+	err = set.Struct(context.Background(), &tt)
+	NotEqual(t, err, nil)
+	Equal(t, err.Error(), "mold: panic applying 'trim' at 'Test.Values[0]': reflect: call of reflect.Value.SetString on int Value")
+
+	// struct level
+	set.RegisterStructTags(Test{}, map[string]string{"Count": "", "Values": ""})
+	set.RegisterStructLevel(func(ctx context.Context, sl StructLevel) error {
+		panic("level")
+	}, Level{})
+
+	err = set.Struct(context.Background(), &tt)
+	NotEqual(t, err, nil)
+	Equal(t, err.Error(), "mold: panic at 'Test.Level': level")
+	Equal(t, errors.As(err, &pe), true)
+	Equal(t, pe.Value, "level")
+	Equal(t, pe.Unwrap(), nil)
+}
+
+func TestStrictKinds(t *testing.T) {
+	type Test struct {
+		Name  *string
+		Names []string          `mold:"dive,trim"`
+		Map   map[string]string `mold:"dive,keys,trim,endkeys,trim"`
+		Iface interface{}       `mold:"trim"`
+		Count int               `mold:"trim"`
+		Time  time.Time         `mold:"now"`
+	}
+
+	set := New()
+	set.Register("trim", func(ctx context.Context, fl FieldLevel) error {
+		fl.Field().SetString(strings.TrimSpace(fl.Field().String()))
+		return nil
+	}, Kinds(reflect.String))
+	set.Register("now", func(ctx context.Context, fl FieldLevel) error {
+		fl.Field().Set(reflect.ValueOf(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)))
+		return nil
+	}, Types(time.Time{}))
+
+	// lenient skips unsupported kinds
+	tt := Test{Names: []string{" a "}, Map: map[string]string{" b ": " c "}, Iface: 1, Count: 1}
+	err := set.Struct(context.Background(), &tt)
+	Equal(t, err, nil)
+	Equal(t, tt.Names[0], "a")
+	Equal(t, tt.Map, map[string]string{"b": "c"})
+	Equal(t, tt.Count, 1)
+	Equal(t, tt.Time.Year(), 2020)
+
+	var i int
+	err = set.Field(context.Background(), &i, "trim")
+	Equal(t, err, nil)
+
+	set.SetStrict(true)
+
+	// checked when building the struct cache
+	err = set.Struct(context.Background(), &tt)
+	NotEqual(t, err, nil)
+	Equal(t, err.Error(), "mold: 'trim' cannot be applied to int at 'Test.Count'")
+
+	var uk *ErrUnsupportedKind
+	Equal(t, errors.As(err, &uk), true)
+	Equal(t, uk.Tag, "trim")
+
+	set.RegisterStructTags(Test{}, map[string]string{"Count": "", "Names": "dive,now"})
+	err = set.Struct(context.Background(), &tt)
+	NotEqual(t, err, nil)
+	Equal(t, err.Error(), "mold: 'now' cannot be applied to string at 'Test.Names[]'")
+
+	set.RegisterStructTags(Test{}, map[string]string{"Count": "", "Map": "dive,keys,now,endkeys"})
+	err = set.Struct(context.Background(), &tt)
+	NotEqual(t, err, nil)
+	Equal(t, err.Error(), "mold: 'now' cannot be applied to string at 'Test.Map[]'")
+
+	// otherwise checked when applied
+	set.RegisterStructTags(Test{}, map[string]string{"Count": "", "Name": "trim"})
+	err = set.Struct(context.Background(), &tt)
+	NotEqual(t, err, nil)
+	Equal(t, err.Error(), "mold: 'trim' cannot be applied to int at 'Test.Iface'")
+
+	tt.Iface = " d "
+	err = set.Struct(context.Background(), &tt)
+	Equal(t, err, nil)
+	Equal(t, tt.Iface, "d")
+
+	err = set.Field(context.Background(), &i, "trim")
+	NotEqual(t, err, nil)
+	Equal(t, err.Error(), "mold: 'trim' cannot be applied to int at ''")
+}
+
+func TestElementWise(t *testing.T) {
+	type Test struct {
+		Slice  []string            `mold:"trim"`
+		Array  [2]string           `mold:"trim"`
+		Map    map[string]string   `mold:"trim"`
+		Nested [][]*string         `mold:"trim"`
+		Iface  []interface{}       `mold:"trim"`
+		Ints   []int               `mold:"trim"`
+		Scalar []string            `mold:"lcase"`
+		Keys   map[string][]string `mold:"dive,keys,trim,endkeys,trim"`
+	}
+
+	set := New()
+	set.Register("trim", func(ctx context.Context, fl FieldLevel) error {
+		fl.Field().SetString(strings.TrimSpace(fl.Field().String()))
+		return nil
+	}, Kinds(reflect.String), ElementWise())
+	set.Register("lcase", func(ctx context.Context, fl FieldLevel) error {
+		fl.Field().SetString(strings.ToLower(fl.Field().String()))
+		return nil
+	}, Kinds(reflect.String))
+
+	s := " c "
+	tt := Test{
+		Slice:  []string{" a ", " b "},
+		Array:  [2]string{" a ", " b "},
+		Map:    map[string]string{" a ": " b "},
+		Nested: [][]*string{{&s, nil}},
+		Iface:  []interface{}{" a ", 1},
+		Ints:   []int{1},
+		Scalar: []string{"A"},
+		Keys:   map[string][]string{" a ": {" b "}},
+	}
+
+	err := set.Struct(context.Background(), &tt)
+	Equal(t, err, nil)
+	Equal(t, tt.Slice, []string{"a", "b"})
+	Equal(t, tt.Array, [2]string{"a", "b"})
+	Equal(t, tt.Map, map[string]string{" a ": "b"})
+	Equal(t, s, "c")
+	Equal(t, tt.Iface, []interface{}{"a", 1})
+	Equal(t, tt.Ints, []int{1})
+	Equal(t, tt.Scalar, []string{"A"})
+	Equal(t, tt.Keys, map[string][]string{"a": {"b"}})
+
+	// namespace of the elements
+	set.Register("trim", func(ctx context.Context, fl FieldLevel) error {
+		return errors.New(fl.Namespace())
+	}, Kinds(reflect.String), ElementWise())
+
+	err = set.Field(context.Background(), &[]string{"a"}, "trim")
+	NotEqual(t, err, nil)
+	Equal(t, err.Error(), "[0]")
+
+	// strict errors for scalar Funcs applied to containers
+	set.SetStrict(true)
+
+	err = set.Struct(context.Background(), &tt)
+	NotEqual(t, err, nil)
+	Equal(t, err.Error(), "mold: 'trim' cannot be applied to []int at 'Test.Ints'")
+
+	set.RegisterStructTags(Test{}, map[string]string{"Ints": ""})
+	err = set.Struct(context.Background(), &tt)
+	NotEqual(t, err, nil)
+	Equal(t, err.Error(), "mold: 'lcase' cannot be applied to []string at 'Test.Scalar', use 'dive' to apply it to the elements")
+}
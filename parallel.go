@@ -0,0 +1,107 @@
+package mold
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// SetConcurrency sets the number of goroutines used to transform the elements of slices and arrays, and the values of maps,
+// when using the dive tag and they contain at least minLen elements. The default of 1 worker transforms them sequentially.
+//
+// When an error occurs the error of the lowest index, or map key in sorted order, is returned. Only the outermost slice,
+// array or map is transformed concurrently, those nested within its elements are transformed sequentially.
+//
+// NOTES:
+// - all registered Func, StructLevelFunc and interceptor functions must be safe for concurrent use.
+// - this method is not thread-safe it is intended that this be set prior to any transformation
+func (t *Transformer) SetConcurrency(workers, minLen int) {
+	if workers < 1 {
+		workers = 1
+	}
+	t.workers = workers
+	t.minParallelLen = minLen
+}
+
+// parallel returns whether n elements at the provided location should be transformed concurrently.
+func (t *Transformer) parallel(loc location, n int) bool {
+	return t.workers > 1 && n > 1 && n >= t.minParallelLen && !loc.parallel
+}
+
+// runParallel calls fn for every index in [0, n) using the configured number of workers, each handling a
+// contiguous range of indexes, and returns the error of the lowest index.
+func (t *Transformer) runParallel(n int, fn func(i int) error) error {
+	workers := t.workers
+	if workers > n {
+		workers = n
+	}
+	chunk := (n + workers - 1) / workers
+
+	errIdx := int64(n)
+	errs := make([]error, n)
+
+	var wg sync.WaitGroup
+
+	for start := 0; start < n; start += chunk {
+		end := start + chunk
+		if end > n {
+			end = n
+		}
+
+		wg.Add(1)
+		go func(start, end int) {
+			defer wg.Done()
+
+			for i := start; i < end; i++ {
+				// an error has already occurred at a lower index
+				if int64(i) > atomic.LoadInt64(&errIdx) {
+					return
+				}
+
+				if err := fn(i); err != nil {
+					errs[i] = err
+					for {
+						cur := atomic.LoadInt64(&errIdx)
+						if int64(i) >= cur || atomic.CompareAndSwapInt64(&errIdx, cur, int64(i)) {
+							break
+						}
+					}
+					return
+				}
+			}
+		}(start, end)
+	}
+	wg.Wait()
+
+	if idx := atomic.LoadInt64(&errIdx); idx < int64(n) {
+		return errs[idx]
+	}
+	return nil
+}
+
+// sortMapKeys sorts the map keys providing a deterministic order.
+func sortMapKeys(keys []reflect.Value) {
+	sort.Slice(keys, func(i, j int) bool {
+		return lessValue(keys[i], keys[j])
+	})
+}
+
+func lessValue(a, b reflect.Value) bool {
+	if a.Kind() == b.Kind() {
+		switch a.Kind() {
+		case reflect.String:
+			return a.String() < b.String()
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return a.Int() < b.Int()
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			return a.Uint() < b.Uint()
+		case reflect.Float32, reflect.Float64:
+			return a.Float() < b.Float()
+		case reflect.Bool:
+			return !a.Bool() && b.Bool()
+		}
+	}
+	return fmt.Sprintf("%v", a.Interface()) < fmt.Sprintf("%v", b.Interface())
+}
@@ -0,0 +1,6 @@
+//go:build race
+
+package mold
+
+// raceEnabled is whether the race detector is enabled, which disables sync.Pool reuse.
+const raceEnabled = true
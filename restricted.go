@@ -3,17 +3,19 @@ package mold
 const (
 	diveTag            = "dive"
 	restrictedTagChars = ".[],|=+()`~!@#$%^&*\\\"/?<>{}"
-	tagSeparator    = ","
-	ignoreTag       = "-"
-	tagKeySeparator = "="
-	utf8HexComma    = "0x2C"
+	tagSeparator       = ","
+	ignoreTag          = "-"
+	tagKeySeparator    = "="
+	utf8HexComma       = "0x2C"
 	keysTag            = "keys"
 	endKeysTag         = "endkeys"
+	promoteTag         = "promote"
 )
 
 var (
 	restrictedTags = map[string]struct{}{
-		diveTag:   {},
-		ignoreTag: {},
+		diveTag:    {},
+		ignoreTag:  {},
+		promoteTag: {},
 	}
 )
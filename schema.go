@@ -0,0 +1,232 @@
+package mold
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const (
+	schemaWildcard     = "*"
+	schemaElemWildcard = "[*]"
+)
+
+// Schema describes the transformations to apply to unstructured data such as the map[string]interface{}
+// and []interface{} values produced by json.Unmarshal. It is created using Transformer.CompileSchema
+// and is safe for concurrent use.
+type Schema struct {
+	root *schemaNode
+}
+
+type schemaNode struct {
+	ct       *cTag
+	keys     map[string]*schemaNode
+	anyKey   *schemaNode
+	indexes  map[int]*schemaNode
+	anyIndex *schemaNode
+}
+
+// CompileSchema compiles rules mapping key paths to tags into a Schema for use with Document.
+//
+// A key path is a dot separated list of map keys, where each key may be followed by one or more
+// array indexes eg. users[0].name, or wildcards matching every key or element eg. users[*].tags[*] or meta.*
+// An empty key path matches the document itself. An empty tag traverses into any struct value found at the key path
+// without applying other transformations and the ignore tag '-' skips the value. When both a wildcard and an exact key or index match,
+// the exact match is used.
+func (t *Transformer) CompileSchema(rules map[string]string) (*Schema, error) {
+	root := new(schemaNode)
+	configured := t.rules()
+
+	for _, path := range sortedKeys(rules) {
+		node := root
+
+		if len(path) > 0 {
+			segments, err := parseSchemaPath(path)
+			if err != nil {
+				return nil, err
+			}
+			for _, seg := range segments {
+				node = node.child(seg)
+			}
+		}
+
+		tag := rules[path]
+		switch tag {
+		case ignoreTag:
+			continue
+		case "":
+			// even without transformations need cTag for traversing to potential inner/nested
+			// struct values.
+			node.ct = &cTag{typeof: typeDefault}
+		default:
+			ct, _, err := t.parseFieldTagsRecursive(configured, tag, path, "", false)
+			if err != nil {
+				return nil, err
+			}
+			node.ct = ct
+		}
+	}
+	return &Schema{root: root}, nil
+}
+
+// schemaSegment represents a single key or index within a key path.
+type schemaSegment struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+func parseSchemaPath(path string) (segments []schemaSegment, err error) {
+	for _, part := range strings.Split(path, ".") {
+		key := part
+		if idx := strings.IndexByte(part, '['); idx != -1 {
+			key = part[:idx]
+		}
+
+		if len(key) == 0 && len(key) == len(part) {
+			return nil, fmt.Errorf("mold: invalid schema path '%s', empty key", path)
+		}
+		if len(key) > 0 {
+			segments = append(segments, schemaSegment{key: key})
+		}
+
+		for rest := part[len(key):]; len(rest) > 0; {
+			end := strings.IndexByte(rest, ']')
+			if rest[0] != '[' || end == -1 {
+				return nil, fmt.Errorf("mold: invalid schema path '%s', malformed index", path)
+			}
+
+			if rest[:end+1] == schemaElemWildcard {
+				segments = append(segments, schemaSegment{key: schemaWildcard, isIndex: true})
+			} else {
+				i, err := strconv.Atoi(rest[1:end])
+				if err != nil || i < 0 {
+					return nil, fmt.Errorf("mold: invalid schema path '%s', invalid index '%s'", path, rest[1:end])
+				}
+				segments = append(segments, schemaSegment{index: i, isIndex: true})
+			}
+			rest = rest[end+1:]
+		}
+	}
+	return
+}
+
+func (n *schemaNode) child(seg schemaSegment) (c *schemaNode) {
+	switch {
+	case seg.isIndex && seg.key == schemaWildcard:
+		if n.anyIndex == nil {
+			n.anyIndex = new(schemaNode)
+		}
+		c = n.anyIndex
+	case seg.isIndex:
+		if n.indexes == nil {
+			n.indexes = make(map[int]*schemaNode)
+		}
+		if c = n.indexes[seg.index]; c == nil {
+			c = new(schemaNode)
+			n.indexes[seg.index] = c
+		}
+	case seg.key == schemaWildcard:
+		if n.anyKey == nil {
+			n.anyKey = new(schemaNode)
+		}
+		c = n.anyKey
+	default:
+		if n.keys == nil {
+			n.keys = make(map[string]*schemaNode)
+		}
+		if c = n.keys[seg.key]; c == nil {
+			c = new(schemaNode)
+			n.keys[seg.key] = c
+		}
+	}
+	return
+}
+
+func (n *schemaNode) key(key string) *schemaNode {
+	if c, ok := n.keys[key]; ok {
+		return c
+	}
+	return n.anyKey
+}
+
+func (n *schemaNode) index(i int) *schemaNode {
+	if c, ok := n.indexes[i]; ok {
+		return c
+	}
+	return n.anyIndex
+}
+
+// Document applies the transformations described by the Schema against the provided value, which must be a
+// non-nil pointer to unstructured data eg. *map[string]interface{}, *[]interface{} or *interface{}.
+// Maps of any value type and slices or arrays of any element type are traversed, any struct values reached
+// by a key path are transformed using their own tags.
+func (t *Transformer) Document(ctx context.Context, v interface{}, s *Schema) error {
+	orig := reflect.ValueOf(v)
+
+	if orig.Kind() != reflect.Ptr || orig.IsNil() {
+		return &ErrInvalidTransformValue{typ: reflect.TypeOf(v), fn: "Document"}
+	}
+	val := orig.Elem()
+
+	return t.setBySchema(ctx, location{top: orig}, val, s.root)
+}
+
+func (t *Transformer) setBySchema(ctx context.Context, loc location, orig reflect.Value, n *schemaNode) (err error) {
+	if n.ct != nil {
+		if err = t.setByField(ctx, loc, orig, n.ct); err != nil {
+			return
+		}
+	}
+
+	if n.keys == nil && n.anyKey == nil && n.indexes == nil && n.anyIndex == nil {
+		return
+	}
+
+	current, kind := t.extractType(orig)
+
+	switch kind {
+	case reflect.Map:
+		for _, key := range current.MapKeys() {
+			name := fmt.Sprintf("%v", key.Interface())
+
+			c := n.key(name)
+			if c == nil {
+				continue
+			}
+
+			newVal := reflect.New(current.Type().Elem()).Elem()
+			newVal.Set(current.MapIndex(key))
+
+			if err = t.setBySchema(ctx, loc.key(name), newVal, c); err != nil {
+				return
+			}
+			current.SetMapIndex(key, newVal)
+		}
+
+	case reflect.Slice, reflect.Array:
+		// slice elements are always addressable, but array elements are only when the array is
+		if kind == reflect.Array && !current.CanAddr() {
+			newVal := reflect.New(current.Type()).Elem()
+			newVal.Set(current)
+			if err = t.setBySchema(ctx, loc, newVal, &schemaNode{indexes: n.indexes, anyIndex: n.anyIndex}); err != nil {
+				return
+			}
+			orig.Set(newVal)
+			return
+		}
+
+		for i := 0; i < current.Len(); i++ {
+			c := n.index(i)
+			if c == nil {
+				continue
+			}
+			if err = t.setBySchema(ctx, loc.indexName(strconv.Itoa(i)), current.Index(i), c); err != nil {
+				return
+			}
+		}
+	}
+	return
+}
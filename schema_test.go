@@ -0,0 +1,126 @@
+package mold
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	. "github.com/go-playground/assert/v2"
+)
+
+func TestSchema(t *testing.T) {
+	type Inner struct {
+		String string `s:"trim"`
+	}
+
+	set := New()
+	set.SetTagName("s")
+	set.Register("trim", func(ctx context.Context, fl FieldLevel) error {
+		if fl.Field().Kind() == reflect.String {
+			fl.Field().SetString(strings.TrimSpace(fl.Field().String()))
+		}
+		return nil
+	})
+	set.Register("ucase", func(ctx context.Context, fl FieldLevel) error {
+		if fl.Field().Kind() == reflect.String {
+			fl.Field().SetString(strings.ToUpper(fl.Field().String()))
+		}
+		return nil
+	})
+	set.Register("err", func(ctx context.Context, fl FieldLevel) error {
+		return errors.New("error at " + fl.Namespace())
+	})
+	set.Register("top", func(ctx context.Context, fl FieldLevel) error {
+		if _, ok := fl.Top().Interface().(*map[string]interface{}); !ok {
+			return errors.New("top is not the provided pointer")
+		}
+		return nil
+	})
+
+	s, err := set.CompileSchema(map[string]string{
+		"user.name":       "trim",
+		"user.ignored":    "-",
+		"items[*].sku":    "trim,ucase",
+		"items[0].sku":    "ucase",
+		"tags[*]":         "trim",
+		"matrix[*][1]":    "ucase",
+		"meta.*":          "trim",
+		"meta.raw":        "",
+		"inner":           "",
+		"missing.key[*]":  "trim",
+		"strings.*.value": "ucase",
+		"top":             "top",
+	})
+	Equal(t, err, nil)
+
+	var doc map[string]interface{}
+	err = json.Unmarshal([]byte(`{
+		"user": {"name": " joe ", "ignored": " joe "},
+		"items": [{"sku": " ab "}, {"sku": " cd "}, "not an object"],
+		"tags": [" a ", " b ", 1],
+		"matrix": [["a", "b"], ["c", "d"]],
+		"meta": {"key": " v ", "raw": " v "},
+		"top": "top"
+	}`), &doc)
+	Equal(t, err, nil)
+
+	err = set.Document(context.Background(), &doc, s)
+	Equal(t, err, nil)
+
+	expected := map[string]interface{}{
+		"user":   map[string]interface{}{"name": "joe", "ignored": " joe "},
+		"items":  []interface{}{map[string]interface{}{"sku": " AB "}, map[string]interface{}{"sku": "CD"}, "not an object"},
+		"tags":   []interface{}{"a", "b", float64(1)},
+		"matrix": []interface{}{[]interface{}{"a", "B"}, []interface{}{"c", "D"}},
+		"meta":   map[string]interface{}{"key": "v", "raw": " v "},
+		"top":    "top",
+	}
+	Equal(t, doc, expected)
+
+	typed := map[string]interface{}{
+		"inner":   &Inner{String: " a "},
+		"strings": map[string]map[string]string{"a": {"value": "a"}},
+	}
+	err = set.Document(context.Background(), &typed, s)
+	Equal(t, err, nil)
+	Equal(t, typed["inner"].(*Inner).String, "a")
+	Equal(t, typed["strings"], map[string]map[string]string{"a": {"value": "A"}})
+
+	arr := [2]string{" a ", " b "}
+	var iface interface{} = arr
+	s, err = set.CompileSchema(map[string]string{"[1]": "trim"})
+	Equal(t, err, nil)
+	err = set.Document(context.Background(), &iface, s)
+	Equal(t, err, nil)
+	Equal(t, iface, [2]string{" a ", "b"})
+
+	s, err = set.CompileSchema(map[string]string{"a.b[*]": "err"})
+	Equal(t, err, nil)
+	doc = map[string]interface{}{"a": map[string]interface{}{"b": []interface{}{"x"}}}
+	err = set.Document(context.Background(), &doc, s)
+	NotEqual(t, err, nil)
+	Equal(t, err.Error(), "error at a.b[0]")
+
+	err = set.Document(context.Background(), doc, s)
+	NotEqual(t, err, nil)
+	Equal(t, err.Error(), "mold: Document(non-pointer map[string]interface {})")
+
+	_, err = set.CompileSchema(map[string]string{"a": "undefined"})
+	NotEqual(t, err, nil)
+	Equal(t, err.Error(), "unregistered/undefined transformation 'undefined' found on field a")
+
+	for path, expected := range map[string]string{
+		"a..b":  "mold: invalid schema path 'a..b', empty key",
+		"a[b]":  "mold: invalid schema path 'a[b]', invalid index 'b'",
+		"a[-1]": "mold: invalid schema path 'a[-1]', invalid index '-1'",
+		"a[0":   "mold: invalid schema path 'a[0', malformed index",
+		"a[0]b": "mold: invalid schema path 'a[0]b', malformed index",
+	} {
+		_, err = set.CompileSchema(map[string]string{path: "trim"})
+		NotEqual(t, err, nil)
+		Equal(t, err.Error(), expected)
+	}
+}
@@ -1,18 +1,24 @@
 package scrubbers
 
 import (
+	"reflect"
+
 	"github.com/go-playground/mold/v4"
 )
 
+// stringOpts declares the scrubbers only accept strings and byte slices eg. []byte and json.RawMessage, and
+// are applied to each element of slices, arrays and maps of them.
+var stringOpts = []mold.RegisterOption{mold.Kinds(reflect.String), mold.Bytes(), mold.ElementWise()}
+
 // New returns a scrubber with defaults registered
 func New() *mold.Transformer {
 	scrub := mold.New()
 	scrub.SetTagName("scrub")
-	scrub.Register("emails", emails)
-	scrub.Register("text", textFn("text"))
-	scrub.Register("email", textFn("email"))
-	scrub.Register("name", textFn("name"))
-	scrub.Register("fname", textFn("fname"))
-	scrub.Register("lname", textFn("lname"))
+	scrub.Register("emails", emails, stringOpts...)
+	scrub.Register("text", textFn("text"), stringOpts...)
+	scrub.Register("email", textFn("email"), stringOpts...)
+	scrub.Register("name", textFn("name"), stringOpts...)
+	scrub.Register("fname", textFn("fname"), stringOpts...)
+	scrub.Register("lname", textFn("lname"), stringOpts...)
 	return scrub
 }
@@ -3,7 +3,6 @@ package scrubbers
 import (
 	"context"
 	"fmt"
-	"reflect"
 	"regexp"
 	"strings"
 
@@ -20,23 +19,13 @@ var (
 )
 
 // emails scrubs all emails found for PII compliance
-func emails(ctx context.Context, fl mold.FieldLevel) error {
-	switch fl.Field().Kind() {
-	case reflect.String:
-		scrubbed := emailRegex.ReplaceAllStringFunc(fl.Field().String(), emailSubmatchFn)
-		fl.Field().SetString(scrubbed)
-	}
-	return nil
-}
+var emails = mold.StringFunc(func(_ context.Context, s string) (string, error) {
+	return emailRegex.ReplaceAllStringFunc(s, emailSubmatchFn), nil
+})
 
 var textFn = func(shaName string) mold.Func {
 	// Text scrubs the whole text for PII compliance
-	return func(ctx context.Context, fl mold.FieldLevel) error {
-		switch fl.Field().Kind() {
-		case reflect.String:
-			scrubbed := fmt.Sprintf("<<scrubbed::%s::sha1::%s>>", shaName, hashString(fl.Field().String()))
-			fl.Field().SetString(scrubbed)
-		}
-		return nil
-	}
+	return mold.StringFunc(func(_ context.Context, s string) (string, error) {
+		return fmt.Sprintf("<<scrubbed::%s::sha1::%s>>", shaName, hashString(s)), nil
+	})
 }
@@ -2,6 +2,7 @@ package scrubbers
 
 import (
 	"context"
+	"encoding/json"
 	"testing"
 
 	. "github.com/go-playground/assert/v2"
@@ -79,3 +80,20 @@ func TestText(t *testing.T) {
 	Equal(t, err, nil)
 	Equal(t, name, "<<scrubbed::name::sha1::028f74c1850aa1efb33a2e8746c0f4183e1e8e30>>")
 }
+
+func TestBytes(t *testing.T) {
+	scrub := New()
+
+	type Test struct {
+		Bytes []byte          `scrub:"text"`
+		Raw   json.RawMessage `scrub:"emails"`
+		Nil   []byte          `scrub:"text"`
+	}
+
+	tt := Test{Bytes: []byte("Joey Bloggs"), Raw: json.RawMessage(`"Dean.Karn@gmail.com"`)}
+	err := scrub.Struct(context.Background(), &tt)
+	Equal(t, err, nil)
+	Equal(t, string(tt.Bytes), "<<scrubbed::text::sha1::028f74c1850aa1efb33a2e8746c0f4183e1e8e30>>")
+	Equal(t, string(tt.Raw), `"<<scrubbed::email::sha1::5131512f2d165ca283b055bc6f32bc01dd23121e>>@gmail.com"`)
+	Equal(t, tt.Nil == nil, true)
+}
@@ -0,0 +1,48 @@
+package mold
+
+import (
+	"database/sql"
+	"reflect"
+)
+
+// sqlNullInner returns a copy of the inner value of a database/sql Null* type, which is always its first field,
+// so that sqlNullWriteBack can tell whether a transformation changed it.
+func sqlNullInner(current reflect.Value) reflect.Value {
+	inner := reflect.New(current.Type().Field(0).Type).Elem()
+	inner.Set(current.Field(0))
+	return inner
+}
+
+// sqlNullWriteBack writes the inner value back to a database/sql Null* type when a transformation changed it,
+// setting the Valid field based on whether it was set or emptied. Valid is left as is otherwise.
+func sqlNullWriteBack(current, inner reflect.Value) error {
+	field := current.Field(0)
+	if reflect.DeepEqual(field.Interface(), inner.Interface()) {
+		return nil
+	}
+	field.Set(inner)
+	current.FieldByName("Valid").SetBool(!inner.IsZero())
+	return nil
+}
+
+// RegisterSQLInterceptors registers interceptors for the database/sql Null* types eg. sql.NullString, applying
+// transformations to the inner value and keeping the Valid field consistent with it; when a transformation changes
+// the inner value Valid is set to true if it was set to a non-zero value and false if it was emptied. Transformations
+// which leave the inner value unchanged never change Valid, so valid zero values eg. {Int64: 0, Valid: true} remain valid.
+//
+// eg. `default=foo` on a null sql.NullString results in {String: "foo", Valid: true} and `empty` results in {String: "", Valid: false}.
+//
+// On Go 1.22+ all instantiations of sql.Null[T] are also supported.
+func (t *Transformer) RegisterSQLInterceptors() {
+	t.RegisterWriteBackInterceptor(sqlNullInner, sqlNullWriteBack,
+		sql.NullBool{},
+		sql.NullByte{},
+		sql.NullFloat64{},
+		sql.NullInt16{},
+		sql.NullInt32{},
+		sql.NullInt64{},
+		sql.NullString{},
+		sql.NullTime{},
+	)
+	t.registerSQLNullGeneric()
+}
@@ -0,0 +1,6 @@
+//go:build !go1.22
+
+package mold
+
+// sql.Null[T] does not exist prior to Go 1.22.
+func (t *Transformer) registerSQLNullGeneric() {}
@@ -0,0 +1,20 @@
+//go:build go1.22
+
+package mold
+
+import (
+	"database/sql"
+	"reflect"
+	"strings"
+)
+
+var sqlNullPkgPath = reflect.TypeOf(sql.Null[int]{}).PkgPath()
+
+// isSQLNull reports whether the type is an instantiation of sql.Null[T].
+func isSQLNull(typ reflect.Type) bool {
+	return typ.Kind() == reflect.Struct && typ.PkgPath() == sqlNullPkgPath && strings.HasPrefix(typ.Name(), "Null[")
+}
+
+func (t *Transformer) registerSQLNullGeneric() {
+	t.RegisterInterceptorMatch(isSQLNull, sqlNullInner, sqlNullWriteBack)
+}
@@ -0,0 +1,46 @@
+//go:build go1.22
+
+package mold
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"strconv"
+	"testing"
+
+	. "github.com/go-playground/assert/v2"
+)
+
+func TestSQLNull(t *testing.T) {
+	set := New()
+	set.RegisterSQLInterceptors()
+	set.Register("set", func(ctx context.Context, fl FieldLevel) error {
+		switch fl.Field().Kind() {
+		case reflect.String:
+			fl.Field().SetString(fl.Param())
+		case reflect.Int:
+			i, _ := strconv.Atoi(fl.Param())
+			fl.Field().SetInt(int64(i))
+		}
+		return nil
+	})
+
+	var n sql.Null[string]
+	err := set.Field(context.Background(), &n, "set=value")
+	Equal(t, err, nil)
+	Equal(t, n, sql.Null[string]{V: "value", Valid: true})
+
+	err = set.Field(context.Background(), &n, "set")
+	Equal(t, err, nil)
+	Equal(t, n, sql.Null[string]{})
+
+	type Test struct {
+		Int sql.Null[int] `mold:"set=5"`
+	}
+
+	var tt Test
+	err = set.Struct(context.Background(), &tt)
+	Equal(t, err, nil)
+	Equal(t, tt.Int, sql.Null[int]{V: 5, Valid: true})
+}
@@ -0,0 +1,127 @@
+package mold
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	. "github.com/go-playground/assert/v2"
+)
+
+func TestSQLInterceptors(t *testing.T) {
+	set := New()
+	set.RegisterSQLInterceptors()
+	set.Register("default", func(ctx context.Context, fl FieldLevel) error {
+		if !fl.Field().IsZero() {
+			return nil
+		}
+		switch fl.Field().Kind() {
+		case reflect.String:
+			fl.Field().SetString("default")
+		case reflect.Int64:
+			fl.Field().SetInt(42)
+		case reflect.Struct:
+			fl.Field().Set(reflect.ValueOf(time.Unix(0, 0)))
+		}
+		return nil
+	})
+	set.Register("empty", func(ctx context.Context, fl FieldLevel) error {
+		fl.Field().Set(reflect.Zero(fl.Field().Type()))
+		return nil
+	})
+
+	type Test struct {
+		String    sql.NullString  `mold:"default"`
+		Int64     sql.NullInt64   `mold:"default"`
+		Time      sql.NullTime    `mold:"default"`
+		Empty     sql.NullString  `mold:"empty"`
+		Ptr       *sql.NullString `mold:"default"`
+		Untouched sql.NullString
+	}
+
+	tt := Test{
+		Empty:     sql.NullString{String: "value", Valid: true},
+		Ptr:       &sql.NullString{},
+		Untouched: sql.NullString{String: "", Valid: true},
+	}
+
+	err := set.Struct(context.Background(), &tt)
+	Equal(t, err, nil)
+	Equal(t, tt.String, sql.NullString{String: "default", Valid: true})
+	Equal(t, tt.Int64, sql.NullInt64{Int64: 42, Valid: true})
+	Equal(t, tt.Time, sql.NullTime{Time: time.Unix(0, 0), Valid: true})
+	Equal(t, tt.Empty, sql.NullString{})
+	Equal(t, *tt.Ptr, sql.NullString{String: "default", Valid: true})
+	Equal(t, tt.Untouched, sql.NullString{String: "", Valid: true})
+
+	ns := sql.NullString{String: "value", Valid: true}
+	err = set.Field(context.Background(), &ns, "empty,default")
+	Equal(t, err, nil)
+	Equal(t, ns, sql.NullString{String: "default", Valid: true})
+
+	// valid zero values are left valid, and null values left null, when unchanged
+	set.Register("noop", func(ctx context.Context, fl FieldLevel) error { return nil })
+
+	type Zero struct {
+		Int64   sql.NullInt64   `mold:"noop"`
+		Bool    sql.NullBool    `mold:"noop"`
+		Float64 sql.NullFloat64 `mold:"empty"`
+		Null    sql.NullString  `mold:"noop"`
+	}
+
+	z := Zero{
+		Int64:   sql.NullInt64{Valid: true},
+		Bool:    sql.NullBool{Valid: true},
+		Float64: sql.NullFloat64{Valid: true},
+		Null:    sql.NullString{String: "", Valid: false},
+	}
+	err = set.Struct(context.Background(), &z)
+	Equal(t, err, nil)
+	Equal(t, z.Int64, sql.NullInt64{Valid: true})
+	Equal(t, z.Bool, sql.NullBool{Valid: true})
+	Equal(t, z.Float64, sql.NullFloat64{Valid: true})
+	Equal(t, z.Null, sql.NullString{})
+}
+
+func TestWriteBackInterceptor(t *testing.T) {
+	type Wrapper struct {
+		value   string
+		changes int
+	}
+
+	set := New()
+	set.RegisterWriteBackInterceptor(func(current reflect.Value) reflect.Value {
+		inner := reflect.New(reflect.TypeOf("")).Elem()
+		inner.SetString(current.Interface().(Wrapper).value)
+		return inner
+	}, func(current, inner reflect.Value) error {
+		w := current.Addr().Interface().(*Wrapper)
+		if inner.String() == "error" {
+			return errors.New("write back error")
+		}
+		w.value = inner.String()
+		w.changes++
+		return nil
+	}, Wrapper{})
+	set.Register("set", func(ctx context.Context, fl FieldLevel) error {
+		fl.Field().SetString(fl.Param())
+		return nil
+	})
+
+	type Test struct {
+		Wrapper Wrapper `mold:"set=a,set=b"`
+	}
+
+	var tt Test
+	err := set.Struct(context.Background(), &tt)
+	Equal(t, err, nil)
+	Equal(t, tt.Wrapper, Wrapper{value: "b", changes: 2})
+
+	var w Wrapper
+	err = set.Field(context.Background(), &w, "set=error")
+	NotEqual(t, err, nil)
+	Equal(t, err.Error(), "write back error")
+}
@@ -0,0 +1,190 @@
+package mold
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+)
+
+// streamFrame holds the state of a JSON object or array currently being streamed.
+type streamFrame struct {
+	node  *schemaNode
+	loc   location
+	array bool
+	index int
+	key   string
+	isKey bool
+	count int
+}
+
+// streamWriter writes JSON tokens to the underlying writer.
+type streamWriter struct {
+	*bufio.Writer
+	scratch bytes.Buffer
+	enc     *json.Encoder
+}
+
+func newStreamWriter(w io.Writer) *streamWriter {
+	sw := &streamWriter{Writer: bufio.NewWriter(w)}
+	sw.enc = json.NewEncoder(&sw.scratch)
+	sw.enc.SetEscapeHTML(false)
+	return sw
+}
+
+func (sw *streamWriter) writeString(s string) error {
+	sw.scratch.Reset()
+	if err := sw.enc.Encode(s); err != nil {
+		return err
+	}
+	// trim the newline added by the encoder
+	_, err := sw.Write(sw.scratch.Bytes()[:sw.scratch.Len()-1])
+	return err
+}
+
+// Stream reads JSON from r, applies the transformations described by the Schema to the string and number
+// values and writes the resulting JSON to w. The JSON is processed token by token so that memory usage
+// is bounded by the nesting depth of the document rather than its size; a sequence of JSON values,
+// such as newline delimited JSON, is written as newline separated values.
+//
+// Numbers are presented to Funcs as a json.Number, which is of kind reflect.String, and must remain a valid JSON number.
+// Because the values are not held in a data structure FieldLevel.Top() and FieldLevel.Struct() return an invalid reflect.Value
+// and key paths targeting objects or arrays, rather than strings or numbers, with tags result in an error unless matched
+// by a wildcard, in which case the object or array is written unchanged.
+func (t *Transformer) Stream(ctx context.Context, r io.Reader, w io.Writer, s *Schema) error {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+
+	sw := newStreamWriter(w)
+
+	var stack []*streamFrame
+	var values int
+
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			if len(stack) > 0 {
+				return io.ErrUnexpectedEOF
+			}
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		var parent *streamFrame
+		if len(stack) > 0 {
+			parent = stack[len(stack)-1]
+		}
+
+		if delim, ok := tok.(json.Delim); ok && (delim == '}' || delim == ']') {
+			_ = sw.WriteByte(byte(delim))
+			stack = stack[:len(stack)-1]
+			if len(stack) > 0 {
+				stack[len(stack)-1].isKey = true
+			}
+			continue
+		}
+
+		if parent != nil && !parent.array && parent.isKey {
+			if parent.count > 0 {
+				_ = sw.WriteByte(',')
+			}
+			parent.count++
+			parent.key = tok.(string)
+			parent.isKey = false
+			if err = sw.writeString(parent.key); err != nil {
+				return err
+			}
+			_ = sw.WriteByte(':')
+			continue
+		}
+
+		var node *schemaNode
+		var loc location
+		var wildcard bool
+
+		switch {
+		case parent == nil:
+			if values > 0 {
+				_ = sw.WriteByte('\n')
+			}
+			values++
+			node = s.root
+		case parent.array:
+			if parent.count > 0 {
+				_ = sw.WriteByte(',')
+			}
+			parent.count++
+			loc = parent.loc.indexName(strconv.Itoa(parent.index))
+			if parent.node != nil {
+				node = parent.node.index(parent.index)
+				wildcard = node != nil && node == parent.node.anyIndex
+			}
+			parent.index++
+		default:
+			loc = parent.loc.key(parent.key)
+			if parent.node != nil {
+				node = parent.node.key(parent.key)
+				wildcard = node != nil && node == parent.node.anyKey
+			}
+			parent.isKey = true
+		}
+
+		if err = contextDone(ctx, loc); err != nil {
+			return err
+		}
+
+		var ct *cTag
+		if node != nil && node.ct != nil && node.ct.hasTag {
+			ct = node.ct
+		}
+
+		switch v := tok.(type) {
+		case json.Delim:
+			if ct != nil && !wildcard {
+				return fmt.Errorf("mold: cannot apply transformations to JSON object or array at '%s' when streaming", loc.ns())
+			}
+			_ = sw.WriteByte(byte(v))
+			stack = append(stack, &streamFrame{node: node, loc: loc, array: v == '[', isKey: v == '{'})
+
+		case string:
+			if ct != nil {
+				val := reflect.New(reflect.TypeOf(v)).Elem()
+				val.SetString(v)
+				if err = t.setByField(ctx, loc, val, ct); err != nil {
+					return err
+				}
+				v = val.String()
+			}
+			if err = sw.writeString(v); err != nil {
+				return err
+			}
+
+		case json.Number:
+			if ct != nil {
+				val := reflect.New(reflect.TypeOf(v)).Elem()
+				val.SetString(string(v))
+				if err = t.setByField(ctx, loc, val, ct); err != nil {
+					return err
+				}
+				v = json.Number(val.String())
+				if len(v) == 0 || (v[0] != '-' && (v[0] < '0' || v[0] > '9')) || !json.Valid([]byte(v)) {
+					return fmt.Errorf("mold: transformed value '%s' at '%s' is not a valid JSON number", v, loc.ns())
+				}
+			}
+			_, _ = sw.WriteString(string(v))
+
+		case bool:
+			_, _ = sw.WriteString(strconv.FormatBool(v))
+
+		case nil:
+			_, _ = sw.WriteString("null")
+		}
+	}
+	return sw.Flush()
+}
@@ -0,0 +1,98 @@
+package mold
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	. "github.com/go-playground/assert/v2"
+)
+
+func TestStream(t *testing.T) {
+	set := New()
+	set.Register("trim", func(ctx context.Context, fl FieldLevel) error {
+		if fl.Field().Kind() == reflect.String {
+			fl.Field().SetString(strings.TrimSpace(fl.Field().String()))
+		}
+		return nil
+	})
+	set.Register("ucase", func(ctx context.Context, fl FieldLevel) error {
+		fl.Field().SetString(strings.ToUpper(fl.Field().String()))
+		return nil
+	})
+	set.Register("set", func(ctx context.Context, fl FieldLevel) error {
+		fl.Field().SetString(fl.Param())
+		return nil
+	})
+	set.Register("err", func(ctx context.Context, fl FieldLevel) error {
+		return errors.New("error at " + fl.Namespace())
+	})
+
+	s, err := set.CompileSchema(map[string]string{
+		"user.name":    "trim,ucase",
+		"user.age":     "set=42",
+		"items[*].sku": "trim",
+		"tags[1]":      "ucase",
+		"meta.*":       "trim",
+		"html":         "trim",
+	})
+	Equal(t, err, nil)
+
+	input := `{"user": {"name": " joe ", "age": 1, "active": true, "nickname": null},
+		"items": [{"sku": " a ", "n": 1.5e3}, {"sku": " b "}, []],
+		"tags": ["a", "b", "c"],
+		"meta": {"k1": " v1 ", "k2": [" v2 "], "k3": {}},
+		"html": " <b>&</b> ",
+		"other": "é\n"}
+		[" x "] "s" 1`
+
+	var buf bytes.Buffer
+	err = set.Stream(context.Background(), strings.NewReader(input), &buf, s)
+	Equal(t, err, nil)
+	Equal(t, buf.String(), `{"user":{"name":"JOE","age":42,"active":true,"nickname":null},"items":[{"sku":"a","n":1.5e3},{"sku":"b"},[]],"tags":["a","B","c"],"meta":{"k1":"v1","k2":[" v2 "],"k3":{}},"html":"<b>&</b>","other":"é\n"}`+"\n"+`[" x "]`+"\n"+`"s"`+"\n"+`1`)
+
+	tests := []struct {
+		rules    map[string]string
+		input    string
+		expected string
+	}{
+		{
+			rules:    map[string]string{"a.b": "set=abc"},
+			input:    `{"a": {"b": 1}}`,
+			expected: "mold: transformed value 'abc' at 'a.b' is not a valid JSON number",
+		},
+		{
+			rules:    map[string]string{"a": "trim"},
+			input:    `{"a": {"b": 1}}`,
+			expected: "mold: cannot apply transformations to JSON object or array at 'a' when streaming",
+		},
+		{
+			rules:    map[string]string{"a[*].b": "err"},
+			input:    `{"a": [{"b": "c"}]}`,
+			expected: "error at a[0].b",
+		},
+		{
+			rules:    map[string]string{"a": "err"},
+			input:    `{"a": 1}`,
+			expected: "error at a",
+		},
+		{
+			rules:    map[string]string{},
+			input:    `{"a": 1`,
+			expected: "unexpected EOF",
+		},
+	}
+
+	for _, tc := range tests {
+		s, err = set.CompileSchema(tc.rules)
+		Equal(t, err, nil)
+
+		buf.Reset()
+		err = set.Stream(context.Background(), strings.NewReader(tc.input), &buf, s)
+		NotEqual(t, err, nil)
+		Equal(t, err.Error(), tc.expected)
+	}
+}
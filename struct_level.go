@@ -1,6 +1,9 @@
 package mold
 
-import "reflect"
+import (
+	"reflect"
+	"sync"
+)
 
 // StructLevel represents the interface for struct level modifier function
 type StructLevel interface {
@@ -19,26 +22,65 @@ type StructLevel interface {
 
 	// Struct returns the value of the current struct being modified.
 	Struct() reflect.Value
+
+	// Top returns the top level value passed to Struct(), Field() or Document(), being the pointer provided.
+	Top() reflect.Value
+
+	// FieldName returns the name of the field containing the current struct within its
+	// enclosing struct, or an empty string for the top level struct.
+	FieldName() string
+
+	// Namespace returns the full path to the current struct starting with the top level
+	// struct name eg. User.Addresses[0].
+	Namespace() string
+
+	// StructField returns the reflect.StructField of the field containing the current struct
+	// within its enclosing struct. The zero value is returned for the top level struct.
+	StructField() reflect.StructField
 }
 
 var (
 	_ StructLevel = (*structLevel)(nil)
 )
 
+// structLevelPool pools the structLevel passed to each StructLevelFunc avoiding an allocation per call.
+var structLevelPool = sync.Pool{
+	New: func() interface{} {
+		return new(structLevel)
+	},
+}
+
 type structLevel struct {
 	transformer *Transformer
 	parent      reflect.Value
 	current     reflect.Value
+	loc         location
 }
 
-func (s structLevel) Transformer() Transform {
+func (s *structLevel) Transformer() Transform {
 	return s.transformer
 }
 
-func (s structLevel) Parent() reflect.Value {
+func (s *structLevel) Parent() reflect.Value {
 	return s.parent
 }
 
-func (s structLevel) Struct() reflect.Value {
+func (s *structLevel) Struct() reflect.Value {
 	return s.current
 }
+
+func (s *structLevel) Top() reflect.Value {
+	return s.loc.top
+}
+
+func (s *structLevel) FieldName() string {
+	return s.loc.fieldName()
+}
+
+func (s *structLevel) Namespace() string {
+	return s.loc.ns()
+}
+
+func (s *structLevel) StructField() reflect.StructField {
+	return s.loc.structField()
+}
@@ -0,0 +1,53 @@
+package mold
+
+import (
+	"encoding"
+	"reflect"
+)
+
+var (
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	stringType          = reflect.TypeOf("")
+)
+
+// RegisterTextInterceptor registers an interceptor against all types implementing both encoding.TextMarshaler and
+// encoding.TextUnmarshaler, eg. netip.Addr or custom enums, so that string transformations such as trim or lcase can be
+// applied to them by round-tripping through their text representation. The value is marshalled to a string, to which the
+// transformations are applied, and the result is unmarshalled back into the value after each transformation.
+//
+// time.Time is excluded so that it continues to be handled as a time.
+//
+// NOTES:
+// - types that are not addressable, eg. held directly within an interface{}, are not updated.
+// - values for which MarshalText returns an error are not intercepted.
+// - this method is not thread-safe it is intended that these all be registered prior to any transformation
+func (t *Transformer) RegisterTextInterceptor() {
+	t.RegisterInterceptorMatch(isText, textInner, textWriteBack)
+}
+
+func isText(typ reflect.Type) bool {
+	return typ != timeType && Implements(textMarshalerType)(typ) && reflect.PtrTo(typ).Implements(textUnmarshalerType)
+}
+
+func textInner(current reflect.Value) reflect.Value {
+	var m encoding.TextMarshaler
+	if current.Type().Implements(textMarshalerType) {
+		m = current.Interface().(encoding.TextMarshaler)
+	} else {
+		m = addressable(current).Interface().(encoding.TextMarshaler)
+	}
+
+	b, err := m.MarshalText()
+	if err != nil {
+		return reflect.Value{}
+	}
+
+	inner := reflect.New(stringType).Elem()
+	inner.SetString(string(b))
+	return inner
+}
+
+func textWriteBack(current, inner reflect.Value) error {
+	return current.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(inner.String()))
+}
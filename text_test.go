@@ -0,0 +1,85 @@
+package mold
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/go-playground/assert/v2"
+)
+
+type emailAddress struct {
+	local  string
+	domain string
+}
+
+func (e emailAddress) MarshalText() ([]byte, error) {
+	if e.local == "marshal" {
+		return nil, errors.New("marshal error")
+	}
+	return []byte(e.local + "@" + e.domain), nil
+}
+
+func (e *emailAddress) UnmarshalText(b []byte) error {
+	idx := strings.IndexByte(string(b), '@')
+	if idx == -1 {
+		return errors.New("invalid email address")
+	}
+	e.local, e.domain = string(b[:idx]), string(b[idx+1:])
+	return nil
+}
+
+func TestTextInterceptor(t *testing.T) {
+	set := New()
+	set.RegisterTextInterceptor()
+	set.Register("lcase", func(ctx context.Context, fl FieldLevel) error {
+		if fl.Field().Kind() == reflect.String {
+			fl.Field().SetString(strings.ToLower(fl.Field().String()))
+		}
+		return nil
+	})
+	set.Register("strip", func(ctx context.Context, fl FieldLevel) error {
+		if fl.Field().Kind() == reflect.String {
+			fl.Field().SetString(strings.Replace(fl.Field().String(), "@", "", -1))
+		}
+		return nil
+	})
+	set.Register("now", func(ctx context.Context, fl FieldLevel) error {
+		fl.Field().Set(reflect.ValueOf(time.Unix(0, 0)))
+		return nil
+	})
+
+	type Test struct {
+		Email  emailAddress    `mold:"lcase"`
+		Emails []*emailAddress `mold:"dive,lcase"`
+		Addr   netip.Addr      `mold:"lcase"`
+		Time   time.Time       `mold:"now"`
+	}
+
+	tt := Test{
+		Email:  emailAddress{local: "Joe", domain: "Example.COM"},
+		Emails: []*emailAddress{{local: "A", domain: "B"}},
+		Addr:   netip.MustParseAddr("2001:DB8::1"),
+	}
+
+	err := set.Struct(context.Background(), &tt)
+	Equal(t, err, nil)
+	Equal(t, tt.Email, emailAddress{local: "joe", domain: "example.com"})
+	Equal(t, *tt.Emails[0], emailAddress{local: "a", domain: "b"})
+	Equal(t, tt.Addr, netip.MustParseAddr("2001:db8::1"))
+	Equal(t, tt.Time, time.Unix(0, 0))
+
+	e := emailAddress{local: "a", domain: "b"}
+	err = set.Field(context.Background(), &e, "strip")
+	NotEqual(t, err, nil)
+	Equal(t, err.Error(), "invalid email address")
+
+	e = emailAddress{local: "marshal", domain: "B"}
+	err = set.Field(context.Background(), &e, "lcase")
+	Equal(t, err, nil)
+	Equal(t, e, emailAddress{local: "marshal", domain: "B"})
+}
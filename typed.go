@@ -0,0 +1,119 @@
+package mold
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// StringFunc returns a Func applying fn to string values, including named string types and byte slices such as
+// []byte and json.RawMessage, removing the need for reflection. Nil pointers, interfaces and byte slices, and values
+// of any other kind, are left unchanged; empty byte slices remain empty, rather than nil, unless changed by fn.
+//
+// eg.
+//
+//	t.Register("trim", mold.StringFunc(func(ctx context.Context, s string) (string, error) {
+//		return strings.TrimSpace(s), nil
+//	}), mold.Kinds(reflect.String))
+func StringFunc(fn func(ctx context.Context, s string) (string, error)) Func {
+	return StringParamFunc(func(ctx context.Context, s, _ string) (string, error) {
+		return fn(ctx, s)
+	})
+}
+
+// StringParamFunc returns a Func applying fn to string values, as StringFunc does, passing the param of the tag.
+func StringParamFunc(fn func(ctx context.Context, s, param string) (string, error)) Func {
+	return func(ctx context.Context, fl FieldLevel) error {
+		field := fl.Field()
+
+		switch {
+		case field.Kind() == reflect.String:
+			s, err := fn(ctx, field.String(), fl.Param())
+			if err != nil {
+				return err
+			}
+			field.SetString(s)
+
+		case isBytes(field.Type()) && !field.IsNil():
+			b := field.Bytes()
+			s, err := fn(ctx, string(b), fl.Param())
+			if err != nil {
+				return err
+			}
+			if s != string(b) {
+				field.SetBytes([]byte(s))
+			}
+		}
+		return nil
+	}
+}
+
+// IntFunc returns a Func applying fn to signed integer values, including named integer types such as time.Duration,
+// returning an error when the result overflows the value's type. Values of any other kind are left unchanged.
+func IntFunc(fn func(ctx context.Context, i int64) (int64, error)) Func {
+	return func(ctx context.Context, fl FieldLevel) error {
+		field := fl.Field()
+
+		switch field.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			i, err := fn(ctx, field.Int())
+			if err != nil {
+				return err
+			}
+			if field.OverflowInt(i) {
+				return fmt.Errorf("mold: %d overflows %s", i, field.Type())
+			}
+			field.SetInt(i)
+		}
+		return nil
+	}
+}
+
+// TimeFunc returns a Func applying fn to time.Time values. Values of any other type are left unchanged.
+func TimeFunc(fn func(ctx context.Context, t time.Time) (time.Time, error)) Func {
+	return TypedFunc(fn)
+}
+
+// TypedFunc returns a Func applying fn to values of type T, or named types of the same kind convertible to it.
+// Values of any other type are left unchanged. A TypedFunc of string behaves as StringFunc.
+func TypedFunc[T any](fn func(ctx context.Context, v T) (T, error)) Func {
+	if f, ok := interface{}(fn).(func(context.Context, string) (string, error)); ok {
+		return StringFunc(f)
+	}
+
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+
+	return func(ctx context.Context, fl FieldLevel) error {
+		field := fl.Field()
+		ft := field.Type()
+
+		if ft != typ && (ft.Kind() != typ.Kind() || !ft.ConvertibleTo(typ) || !typ.ConvertibleTo(ft)) {
+			return nil
+		}
+
+		in := field
+		if ft != typ {
+			in = field.Convert(typ)
+		}
+
+		val, _ := in.Interface().(T)
+
+		v, err := fn(ctx, val)
+		if err != nil {
+			return err
+		}
+
+		out := reflect.ValueOf(&v).Elem()
+		if ft != typ {
+			out = out.Convert(ft)
+		}
+		field.Set(out)
+		return nil
+	}
+}
+
+// isBytes reports whether the type is a byte slice eg. []byte or json.RawMessage.
+func isBytes(typ reflect.Type) bool {
+	return typ.Kind() == reflect.Slice && typ.Elem().Kind() == reflect.Uint8
+}
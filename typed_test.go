@@ -0,0 +1,117 @@
+package mold
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/go-playground/assert/v2"
+)
+
+type namedString string
+
+type namedInt int
+
+func TestTypedFuncs(t *testing.T) {
+	type Test struct {
+		String  string          `mold:"trim"`
+		Named   namedString     `mold:"trim"`
+		Ptr     *string         `mold:"trim"`
+		NilPtr  *string         `mold:"trim"`
+		Bytes   []byte          `mold:"trim"`
+		NilRaw  json.RawMessage `mold:"trim"`
+		Raw     json.RawMessage `mold:"trim"`
+		Iface   interface{}     `mold:"trim"`
+		Int     int             `mold:"trim"`
+		Prefix  string          `mold:"prefix=x"`
+		Double  namedInt        `mold:"double"`
+		Int8    int8            `mold:"double"`
+		Dur     time.Duration   `mold:"double"`
+		Time    time.Time       `mold:"utc"`
+		Typed   namedString     `mold:"typed"`
+		Generic string          `mold:"generic"`
+	}
+
+	set := New()
+	set.Register("trim", StringFunc(func(ctx context.Context, s string) (string, error) {
+		return strings.TrimSpace(s), nil
+	}))
+	set.Register("prefix", StringParamFunc(func(ctx context.Context, s, param string) (string, error) {
+		return param + s, nil
+	}))
+	set.Register("double", IntFunc(func(ctx context.Context, i int64) (int64, error) {
+		return i * 2, nil
+	}))
+	set.Register("utc", TimeFunc(func(ctx context.Context, t time.Time) (time.Time, error) {
+		return t.UTC(), nil
+	}))
+	set.Register("typed", TypedFunc(func(ctx context.Context, s namedString) (namedString, error) {
+		return s + "!", nil
+	}))
+	set.Register("generic", TypedFunc(func(ctx context.Context, s string) (string, error) {
+		return strings.ToUpper(s), nil
+	}))
+
+	s := " ptr "
+	tt := Test{
+		String:  " a ",
+		Named:   " b ",
+		Ptr:     &s,
+		Bytes:   []byte(" c "),
+		Raw:     json.RawMessage(" {} "),
+		Iface:   " d ",
+		Int:     1,
+		Prefix:  "y",
+		Double:  2,
+		Int8:    3,
+		Dur:     time.Second,
+		Time:    time.Date(2020, 1, 1, 0, 0, 0, 0, time.FixedZone("x", 3600)),
+		Typed:   "e",
+		Generic: "f",
+	}
+
+	err := set.Struct(context.Background(), &tt)
+	Equal(t, err, nil)
+	Equal(t, tt.String, "a")
+	Equal(t, tt.Named, namedString("b"))
+	Equal(t, *tt.Ptr, "ptr")
+	Equal(t, tt.NilPtr, nil)
+	Equal(t, string(tt.Bytes), "c")
+	Equal(t, tt.NilRaw == nil, true)
+	Equal(t, string(tt.Raw), "{}")
+	Equal(t, tt.Iface, "d")
+	Equal(t, tt.Int, 1)
+	Equal(t, tt.Prefix, "xy")
+	Equal(t, tt.Double, namedInt(4))
+	Equal(t, tt.Int8, int8(6))
+	Equal(t, tt.Dur, 2*time.Second)
+	Equal(t, tt.Time, time.Date(2019, 12, 31, 23, 0, 0, 0, time.UTC))
+	Equal(t, tt.Typed, namedString("e!"))
+	Equal(t, tt.Generic, "F")
+
+	// empty byte slices remain non-nil
+	b := []byte{}
+	err = set.Field(context.Background(), &b, "trim")
+	Equal(t, err, nil)
+	Equal(t, b == nil, false)
+
+	// overflow
+	i8 := int8(math.MaxInt8)
+	err = set.Field(context.Background(), &i8, "double")
+	NotEqual(t, err, nil)
+	Equal(t, err.Error(), "mold: 254 overflows int8")
+
+	// errors
+	set.Register("fail", StringFunc(func(ctx context.Context, s string) (string, error) {
+		return "changed", errors.New("failed")
+	}))
+	str := "a"
+	err = set.Field(context.Background(), &str, "fail")
+	NotEqual(t, err, nil)
+	Equal(t, err.Error(), "failed")
+	Equal(t, str, "a")
+}
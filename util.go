@@ -4,27 +4,59 @@ import (
 	"reflect"
 )
 
+// writeBack holds an intercepted value and the inner value returned by its interceptor.
+type writeBack struct {
+	current reflect.Value
+	inner   reflect.Value
+	fn      WriteBackFunc
+}
+
 // extractType gets the actual underlying type of field value.
 func (t *Transformer) extractType(current reflect.Value) (reflect.Value, reflect.Kind) {
+	current, kind, _ := t.extractTypeWriteBack(current, nil)
+	return current, kind
+}
+
+// extractTypeWriteBack gets the actual underlying type of field value, appending the intercepted values
+// that require writing back after modification of the inner value.
+func (t *Transformer) extractTypeWriteBack(current reflect.Value, wbs []writeBack) (reflect.Value, reflect.Kind, []writeBack) {
 	switch current.Kind() {
 	case reflect.Ptr:
 		if current.IsNil() {
-			return current, reflect.Ptr
+			return current, reflect.Ptr, wbs
 		}
-		return t.extractType(current.Elem())
+		return t.extractTypeWriteBack(current.Elem(), wbs)
 
 	case reflect.Interface:
 		if current.IsNil() {
-			return current, reflect.Interface
+			return current, reflect.Interface, wbs
 		}
-		return t.extractType(current.Elem())
+		return t.extractTypeWriteBack(current.Elem(), wbs)
 
 	default:
-		if fn := t.interceptors[current.Type()]; fn != nil {
-			return t.extractType(fn(current))
+		if ic := t.interceptor(current.Type()); ic != nil {
+			inner := ic.fn(current)
+			if !inner.IsValid() {
+				// interceptor declined to intercept the value
+				return current, current.Kind(), wbs
+			}
+			if ic.writeBack != nil && current.CanAddr() {
+				wbs = append(wbs, writeBack{current: current, inner: inner, fn: ic.writeBack})
+			}
+			return t.extractTypeWriteBack(inner, wbs)
+		}
+		return current, current.Kind(), wbs
+	}
+}
+
+// runWriteBacks calls the WriteBackFuncs of the intercepted values, innermost first.
+func runWriteBacks(wbs []writeBack) error {
+	for i := len(wbs) - 1; i >= 0; i-- {
+		if err := wbs[i].fn(wbs[i].current, wbs[i].inner); err != nil {
+			return err
 		}
-		return current, current.Kind()
 	}
+	return nil
 }
 
 // HasValue determines if a reflect.Value is it's default value